@@ -18,10 +18,14 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 	"ula-tools/internal/ula-client/dwmapi"
 	. "ula-tools/internal/ulog"
@@ -35,15 +39,115 @@ Options:
   -c      specify a dwm api command (default: DwmSetSystemLayout)
           DwmSetSystemLayout           no arguments
           DwmSetLayoutCommand          filePath
+          DwmGetLayout                 [all | nodeId ...]
+                                       print the current layout, and the pixel screens
+                                       of all nodes or the given nodes
+          DwmWatchLayout               print layout change events until interrupted
+          DwmUndoLayout                no arguments
+                                       re-apply the layout before the current one
+          DwmRevertToGeneration        generation
+                                       re-apply the layout of the generation
+          DwmGetClusterStatus          no arguments
+                                       print the status of each ula-node
+          DwmWatchNodeState            print up/down events of ula-nodes until interrupted
+  -n      dry run: print the commands which each ula-node would receive and an
+          approximation of the messages to its compositors, generated by the manager
+          from its view of the applied layout, without applying them
+          (DwmSetLayoutCommand only)
   -h      Show this message
 `
 	fmt.Println(usage)
 }
 
+func printJson(jsonStr string) {
+	var out bytes.Buffer
+	err := json.Indent(&out, []byte(jsonStr), "", "  ")
+	if err != nil {
+		fmt.Println(jsonStr)
+		return
+	}
+	fmt.Println(out.String())
+}
+
+func getLayout(client dwm.DwmServiceClient, ctx context.Context, args []string) error {
+	withNodePixelScreens := len(args) != 0
+	nodeIds := make([]int32, 0)
+	if !(len(args) == 1 && args[0] == "all") {
+		for _, arg := range args {
+			nodeId, err := strconv.Atoi(arg)
+			if err != nil {
+				return errors.New(fmt.Sprintf("invalid nodeId: %s", arg))
+			}
+			nodeIds = append(nodeIds, int32(nodeId))
+		}
+	}
+
+	resp, err := dwmapi.DwmClientGetLayout(client, ctx, withNodePixelScreens, nodeIds)
+	if err != nil {
+		return err
+	}
+
+	printJson(resp.GetLayout())
+	for _, nps := range resp.GetNodePixelScreens() {
+		fmt.Printf("node %d:\n", nps.GetNodeId())
+		printJson(nps.GetPixelScreens())
+	}
+
+	return nil
+}
+
+func printLayoutEvent(event *dwm.LayoutEvent) {
+	chgIds := make([]string, 0)
+	for _, idPair := range event.GetChgIds() {
+		chgIds = append(chgIds, fmt.Sprintf("%d:%d", idPair.GetLayerId(), idPair.GetSurfaceId()))
+	}
+	fmt.Printf("generation=%d command=%s chg_ids=%v\n", event.GetGeneration(), event.GetCommand(), chgIds)
+}
+
+func printNodeStateEvent(event *dwm.NodeStateEvent) {
+	state := "down"
+	if event.GetUp() {
+		state = "up"
+	}
+	since := time.UnixMilli(event.GetSinceUnixMs()).Format(time.RFC3339)
+	fmt.Printf("node %d (%s): %s since %s reason=%q\n", event.GetNodeId(), event.GetTargetAddr(), state, since, event.GetReason())
+}
+
+func printClusterStatus(resp *dwm.ClusterStatusResponse) {
+	fmt.Printf("generation=%d\n", resp.GetGeneration())
+	for _, node := range resp.GetNodes() {
+		if !node.GetReachable() {
+			fmt.Printf("node %d (%s): unreachable error=%q\n", node.GetNodeId(), node.GetTargetAddr(), node.GetError())
+			continue
+		}
+		fmt.Printf("node %d (%s): plugin=%s generation=%d uptime=%ds\n",
+			node.GetNodeId(), node.GetTargetAddr(), node.GetPluginType(), node.GetGeneration(), node.GetUptimeSec())
+		for _, rdisplay := range node.GetRdisplays() {
+			fmt.Printf("  rdisplay %d: connected=%t reconnects=%d last_resync_result=%d\n",
+				rdisplay.GetRdisplayId(), rdisplay.GetConnected(), rdisplay.GetReconnects(), rdisplay.GetLastResyncResult())
+		}
+	}
+}
+
+func printDryRunResults(resp *dwm.Response) {
+	for _, result := range resp.GetDryRunResults() {
+		fmt.Printf("node %d (%s):\n", result.GetNodeId(), result.GetPluginType())
+		printJson(result.GetApplyCommandData())
+		for _, comm := range result.GetCompositorCommands() {
+			fmt.Printf("  compositor %s rdisplays=%v:\n", comm.GetCompositor(), comm.GetRdisplayIds())
+			for _, msg := range comm.GetMessages() {
+				printJson(msg)
+			}
+		}
+	}
+}
+
 func main() {
 	var command string
+	var dryRun bool
 	var showHelp bool
 	flag.StringVar(&command, "c", "DwmSetSystemLayout", "Command to execute (e.g., DwmSetSystemLayout, etc.)")
+	flag.BoolVar(&dryRun, "n", false, "Dry run of DwmSetLayoutCommand")
 	flag.BoolVar(&showHelp, "h", false, "Show this message")
 	flag.Parse()
 	args := flag.Args()
@@ -64,6 +168,24 @@ func main() {
 	defer conn.Close()
 	client := dwm.NewDwmServiceClient(conn)
 
+	if command == "DwmWatchLayout" {
+		/* the stream is kept until the process is interrupted */
+		err = dwmapi.DwmClientWatchLayout(client, context.Background(), printLayoutEvent)
+		if err != nil {
+			ELog.Printf("Error calling WatchLayout: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if command == "DwmWatchNodeState" {
+		err = dwmapi.DwmClientWatchNodeState(client, context.Background(), printNodeStateEvent)
+		if err != nil {
+			ELog.Printf("Error calling WatchNodeState: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -79,10 +201,53 @@ func main() {
 			os.Exit(1)
 		}
 		layoutCommandFilePath := args[0]
+		if dryRun {
+			resp, err := dwmapi.DwmClientDryRunLayoutCommand(client, ctx, layoutCommandFilePath)
+			if err != nil {
+				ELog.Printf("Error calling SetLayoutCommand: %v", err)
+				os.Exit(1)
+			}
+			printDryRunResults(resp)
+			break
+		}
 		err = dwmapi.DwmClientSetLayoutCommand(client, ctx, layoutCommandFilePath)
 		if err != nil {
 			ELog.Printf("Error calling SetLayoutCommand: %v", err)
 		}
+	case "DwmGetLayout":
+		err = getLayout(client, ctx, args)
+		if err != nil {
+			ELog.Printf("Error calling GetLayout: %v", err)
+			os.Exit(1)
+		}
+	case "DwmUndoLayout":
+		err = dwmapi.DwmClientUndoLayout(client, ctx)
+		if err != nil {
+			ELog.Printf("Error calling UndoLayout: %v", err)
+			os.Exit(1)
+		}
+	case "DwmRevertToGeneration":
+		if len(args) < 1 {
+			ELog.Printf("DwmRevertToGeneration requires an argument: generation")
+			os.Exit(1)
+		}
+		generation, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			ELog.Printf("invalid generation: %s", args[0])
+			os.Exit(1)
+		}
+		err = dwmapi.DwmClientRevertToGeneration(client, ctx, generation)
+		if err != nil {
+			ELog.Printf("Error calling RevertToGeneration: %v", err)
+			os.Exit(1)
+		}
+	case "DwmGetClusterStatus":
+		resp, err := dwmapi.DwmClientGetClusterStatus(client, ctx)
+		if err != nil {
+			ELog.Printf("Error calling GetClusterStatus: %v", err)
+			os.Exit(1)
+		}
+		printClusterStatus(resp)
 	default:
 		err = dwmapi.DwmClientSetSystemLayout(client, ctx)
 		if err != nil {
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"ula-tools/internal/ula"
+	"ula-tools/internal/ula-node"
+	"ula-tools/internal/ula-node/iviwinmgr"
+	. "ula-tools/internal/ulog"
+)
+
+func writeDebugJson(w http.ResponseWriter, v interface{}) {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonBytes)
+}
+
+func newDebugMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/ula/pixel-screens", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJson(w, ulanode.GetAppliedNPScreens(nil))
+	})
+	mux.HandleFunc("/debug/ula/split-layer-ids", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJson(w, iviwinmgr.GetPLayerSplitIDs())
+	})
+	mux.HandleFunc("/debug/ula/compositors", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJson(w, ulanode.GetCompositorStatuses())
+	})
+	mux.HandleFunc("/debug/ula/commands", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJson(w, ulanode.GetCommandRecords())
+	})
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return mux
+}
+
+/* serves the introspection endpoint on localhost if "debug" of the node is true */
+func startDebugServer(vscrnDef *ula.VScrnDef, nodeId int) {
+	debug, debugPort := vscrnDef.GetDebugPort(nodeId)
+	if !debug {
+		return
+	}
+
+	debugAddr := "127.0.0.1:" + strconv.Itoa(debugPort)
+	listener, err := net.Listen("tcp", debugAddr)
+	if err != nil {
+		ELog.Printf("Debug server listen error: %s", err)
+		return
+	}
+
+	ILog.Println("Debug server listening on ", debugAddr)
+	go func() {
+		err := http.Serve(listener, newDebugMux())
+		if err != nil {
+			ELog.Printf("Debug server error: %s", err)
+		}
+	}()
+}
@@ -32,7 +32,7 @@ import (
 	"strconv"
 	_ "strings"
 	"sync"
-	_ "time"
+	"time"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-node"
 	"ula-tools/internal/ula-node/iviwinmgr"
@@ -42,13 +42,15 @@ import (
 
 var MAGIC_CODE []byte = []byte{0x55, 0x4C, 0x41, 0x30} // 'ULA0' ascii code
 
+/* the layout commands of a connection which wait for the previous one */
+const CMD_QUEUE_SIZE = 4
+
 var Mutex struct {
 	sync.Mutex
 }
 
-func readConnection(conn net.Conn) ([]byte, uint32, error) {
-
-	cbio := bufio.NewReader(conn)
+/* cbio is kept for the connection, since it may have read the next request already */
+func readConnection(cbio *bufio.Reader) ([]byte, uint32, error) {
 
 	magicBuf := make([]byte, 4)
 
@@ -84,12 +86,66 @@ func readConnection(conn net.Conn) ([]byte, uint32, error) {
 	return recvBuf, recvSize, nil
 }
 
-func readCommandLoop(conn net.Conn, jsonChan chan map[string]interface{}, listenerId int, retChansMap map[int]interface{}) {
+/* writeMutex serializes the responses of the command worker and the reader on the connection */
+func writeResponse(conn net.Conn, writeMutex *sync.Mutex, retJson map[string]interface{}) {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	retBuf, _ := json.Marshal(retJson)
+	msgLen := uint32(len(retBuf))
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, msgLen)
+	n, err := conn.Write([]byte(size))
+	if err != nil || n == 0 {
+		ELog.Printf("Write DATA Size error: %s \n", err)
+	}
+
+	n, err = conn.Write(retBuf)
+	if err != nil || n == 0 {
+		ELog.Printf("Write error: %s \n", err)
+	}
+}
+
+/* forwards the commands of the connection to processCommandLoop one by one and writes their results */
+func commandWorker(conn net.Conn, writeMutex *sync.Mutex, cmdQueue chan map[string]interface{}, jsonChan chan map[string]interface{}, listenerId int, retChansMap map[int]interface{}) {
+	Mutex.Lock()
+	retChan := retChansMap[listenerId].(chan map[string]interface{})
+	Mutex.Unlock()
+
+	for mJson := range cmdQueue {
+		seq := mJson["seq"]
+		jsonChan <- mJson
+		retJson := <-retChan
+		if seq != nil {
+			retJson["seq"] = seq
+		}
+		writeResponse(conn, writeMutex, retJson)
+	}
+
+	Mutex.Lock()
+	delete(retChansMap, listenerId)
+	Mutex.Unlock()
+}
+
+/*
+ * The layout commands are processed by commandWorker, so the status and the
+ * heartbeat are answered at once, even while a layout command of the same
+ * connection is processed. Their responses can come before the one of the
+ * command. The seq of the request is returned in its response, so the client
+ * can drop the late response of a request which has timed out.
+ */
+func readCommandLoop(conn net.Conn, jsonChan chan map[string]interface{}, listenerId int, retChansMap map[int]interface{}, statusFunc func() *ula.NodeStatus) {
+
+	var writeMutex sync.Mutex
+	cmdQueue := make(chan map[string]interface{}, CMD_QUEUE_SIZE)
+	go commandWorker(conn, &writeMutex, cmdQueue, jsonChan, listenerId, retChansMap)
 
 	defer conn.Close()
+	defer close(cmdQueue)
 
+	cbio := bufio.NewReader(conn)
 	for {
-		recvBuf, recvSize, err := readConnection(conn)
+		recvBuf, recvSize, err := readConnection(cbio)
 		if err != nil {
 			if err == io.EOF {
 				DLog.Printf("Ula-node zero byte read(maybe Client closed the connection)\n")
@@ -105,35 +161,98 @@ func readCommandLoop(conn net.Conn, jsonChan chan map[string]interface{}, listen
 			break
 		}
 
+		if mJson["type"] == ula.REQUEST_TYPE_STATUS {
+			writeResponse(conn, &writeMutex, map[string]interface{}{
+				"type":   ula.REQUEST_TYPE_STATUS,
+				"result": 0,
+				"status": statusFunc(),
+				"seq":    mJson["seq"],
+			})
+			continue
+		}
+		if mJson["type"] == ula.REQUEST_TYPE_HEARTBEAT {
+			writeResponse(conn, &writeMutex, map[string]interface{}{
+				"type":   ula.REQUEST_TYPE_HEARTBEAT,
+				"result": 0,
+				"seq":    mJson["seq"],
+			})
+			continue
+		}
+
 		mJson["listener_id"] = listenerId
 
-		jsonChan <- mJson
+		cmdQueue <- mJson
+	}
+}
 
-		Mutex.Lock()
-		retChan := retChansMap[listenerId].(chan map[string]interface{})
-		Mutex.Unlock()
-		select {
-		case retJson := <-retChan:
-			retBuf, _ := json.Marshal(retJson)
-			msgLen := uint32(len(retBuf))
-			size := make([]byte, 4)
-			binary.BigEndian.PutUint32(size, msgLen)
-			n, err := conn.Write([]byte(size))
-			if err != nil || n == 0 {
-				ELog.Printf("Write DATA Size error: %s \n", err)
-			}
+/* saves the applied layout, the state file is disabled if stateFile is empty */
+func saveNodeState(stateFile string, acdata *ula.ApplyCommandData) {
+	if stateFile == "" {
+		return
+	}
+	err := ulanode.SaveNodeState(stateFile, acdata)
+	if err != nil {
+		ELog.Printf("SaveNodeState error: %s", err)
+	}
+}
 
-			n, err = conn.Write(retBuf)
-			if err != nil || n == 0 {
-				ELog.Printf("Write error: %s \n", err)
-			}
-			break
+/*
+ * Loads the layout which was applied before the restart. If restoreLayout is
+ * true, it is pushed to the compositor again. The NodePixelScreens of the
+ * returned ApplyCommandData are the ones which the next command is diffed against.
+ */
+func restoreNodeState(
+	nodeId int,
+	stateFile string,
+	restoreLayout bool,
+	reqChan chan ulanode.LocalCommandReq,
+	respChan chan ulanode.LocalCommandReq,
+	plugin ulanode.LocalCommandGenerator,
+) *ula.ApplyCommandData {
+	emptyAcdata := &ula.ApplyCommandData{NPScreens: new(ula.NodePixelScreens)}
+	if stateFile == "" {
+		return emptyAcdata
+	}
+
+	acdata, err := ulanode.LoadNodeState(stateFile, nodeId)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			WLog.Printf("LoadNodeState error: %s", err)
 		}
+		return emptyAcdata
+	}
+	ILog.Printf("Loaded the last layout (%s) from %s", acdata.Command, stateFile)
 
+	if !restoreLayout {
+		return acdata
 	}
-	Mutex.Lock()
-	delete(retChansMap, listenerId)
-	Mutex.Unlock()
+
+	/* the whole layout is sent as initial_vscreen, since the compositor state is unknown */
+	racdata := &ula.ApplyCommandData{
+		Command:   "initial_vscreen",
+		NPScreens: acdata.NPScreens,
+	}
+	reqs, err := plugin.GenerateLocalCommandReq(racdata, new(ula.NodePixelScreens))
+	if err != nil {
+		ELog.Printf("Restore layout error: %s", err)
+		return emptyAcdata
+	}
+	ret := submitCommand(reqs, reqChan, respChan)
+	if ret != 0 {
+		ELog.Printf("Restore layout failed: %d", ret)
+		return emptyAcdata
+	}
+	ILog.Println("Restored the last layout to the compositor")
+
+	return acdata
+}
+
+/* the command which was prepared and waits for commit */
+type pendingCommand struct {
+	txId   uint64
+	acdata *ula.ApplyCommandData
+	reqs   []*ulanode.LocalCommandReq
+	state  ulanode.GeneratorState
 }
 
 func processCommandLoop(
@@ -143,8 +262,18 @@ func processCommandLoop(
 	jsonChan chan map[string]interface{},
 	retChansMap map[int]interface{},
 	plugin ulanode.LocalCommandGenerator,
+	stateFile string,
+	restoreLayout bool,
 ) {
-	spscrns := new(ula.NodePixelScreens)
+	restored := restoreNodeState(nodeId, stateFile, restoreLayout, reqChan, respChan, plugin)
+	spscrns := restored.NPScreens
+	generation := restored.Generation
+	ulanode.SetAppliedLayout(spscrns, generation)
+	prevSpscrns := spscrns
+	prevGeneration := generation
+	var prevState ulanode.GeneratorState
+	var committedTxId uint64
+	var pending *pendingCommand
 	for {
 		var mJson map[string]interface{}
 		select {
@@ -169,17 +298,101 @@ func processCommandLoop(
 			continue
 		}
 
-		reqs, err := plugin.GenerateLocalCommandReq(acdata, spscrns)
-		if err != nil {
-			ret = -1
-			commResponseResult(ret, listenerId, retChansMap)
-			continue
+		startTime := time.Now()
+		record := ulanode.CommandRecord{
+			Time:    startTime,
+			Phase:   acdata.Phase,
+			TxId:    acdata.TxId,
+			Command: acdata.Command,
+			ChgIds:  acdata.ChgIds,
 		}
 
-		ret = submitCommand(reqs, reqChan, respChan)
+		switch acdata.Phase {
+		case ula.PHASE_PREPARE:
+			/* the generator state is not changed until commit, so abort has nothing to undo */
+			reqs, state, err := plugin.PrepareLocalCommandReq(acdata, spscrns)
+			if err != nil {
+				ELog.Printf("Prepare TxId %d error: %s", acdata.TxId, err)
+				ret = -1
+				break
+			}
+			pending = &pendingCommand{txId: acdata.TxId, acdata: acdata, reqs: reqs, state: state}
 
-		spscrns = acdata.NPScreens
+		case ula.PHASE_COMMIT:
+			if pending == nil || pending.txId != acdata.TxId {
+				ELog.Printf("Commit TxId %d is not prepared", acdata.TxId)
+				ret = -1
+				break
+			}
+			record.Command = pending.acdata.Command
+			record.ChgIds = pending.acdata.ChgIds
+			committingState := plugin.SwapGeneratorState(pending.state)
+			ret = submitCommand(pending.reqs, reqChan, respChan)
+			if ret != 0 {
+				/* the node keeps the layout before the commit */
+				ELog.Printf("Commit TxId %d failed: %d", acdata.TxId, ret)
+				plugin.SwapGeneratorState(committingState)
+				pending = nil
+				break
+			}
+			saveNodeState(stateFile, pending.acdata)
+			prevSpscrns = spscrns
+			prevGeneration = generation
+			prevState = committingState
+			spscrns = pending.acdata.NPScreens
+			generation = pending.acdata.Generation
+			committedTxId = pending.txId
+			pending = nil
+
+		case ula.PHASE_ABORT:
+			if pending != nil && pending.txId == acdata.TxId {
+				pending = nil
+				break
+			}
+			if committedTxId == 0 || committedTxId != acdata.TxId {
+				break
+			}
+			/* roll back to the layout and the generator state before the commit */
+			racdata := &ula.ApplyCommandData{
+				Command:    "rollback",
+				NPScreens:  prevSpscrns,
+				Generation: prevGeneration,
+			}
+			committedState := plugin.SwapGeneratorState(prevState)
+			reqs, err := plugin.GenerateLocalCommandReq(racdata, spscrns)
+			if err != nil {
+				ELog.Printf("Rollback TxId %d error: %s", acdata.TxId, err)
+				plugin.SwapGeneratorState(committedState)
+				ret = -1
+				break
+			}
+			ret = submitCommand(reqs, reqChan, respChan)
+			if ret == 0 {
+				saveNodeState(stateFile, racdata)
+			}
+			spscrns = prevSpscrns
+			generation = prevGeneration
+			committedTxId = 0
 
+		default:
+			reqs, err := plugin.GenerateLocalCommandReq(acdata, spscrns)
+			if err != nil {
+				ret = -1
+				break
+			}
+			ret = submitCommand(reqs, reqChan, respChan)
+			if ret == 0 {
+				saveNodeState(stateFile, acdata)
+			}
+			spscrns = acdata.NPScreens
+			generation = acdata.Generation
+			committedTxId = 0
+		}
+
+		ulanode.SetAppliedLayout(spscrns, generation)
+		record.Result = ret
+		record.Latency = time.Since(startTime)
+		ulanode.RecordCommand(record)
 		commResponseResult(ret, listenerId, retChansMap)
 	}
 }
@@ -195,7 +408,9 @@ func submitCommand(
 		reqChan <- *req
 		select {
 		case lcr := <-respChan:
-			ret = lcr.Ret
+			if lcr.Ret != 0 {
+				ret = lcr.Ret
+			}
 			break
 		}
 	}
@@ -222,11 +437,14 @@ func mainLoop(
 	nodeId int,
 	reqChan chan ulanode.LocalCommandReq,
 	respChan chan ulanode.LocalCommandReq,
-	plugin ulanode.LocalCommandGenerator) {
+	plugin ulanode.LocalCommandGenerator,
+	stateFile string,
+	restoreLayout bool,
+	statusFunc func() *ula.NodeStatus) {
 
 	jsonChan := make(chan map[string]interface{}, 1)
 	retChansMap := make(map[int]interface{})
-	go processCommandLoop(nodeId, reqChan, respChan, jsonChan, retChansMap, plugin)
+	go processCommandLoop(nodeId, reqChan, respChan, jsonChan, retChansMap, plugin, stateFile, restoreLayout)
 
 	listenerId := 0
 	for {
@@ -239,7 +457,7 @@ func mainLoop(
 		Mutex.Lock()
 		retChansMap[listenerId] = retChan
 		Mutex.Unlock()
-		go readCommandLoop(conn, jsonChan, listenerId, retChansMap)
+		go readCommandLoop(conn, jsonChan, listenerId, retChansMap, statusFunc)
 		listenerId += 1
 	}
 }
@@ -260,6 +478,8 @@ func main() {
 		vScrnDefFile string
 		keyNodeId    int
 		keyHostName  string
+		stateFile    string
+		restore      bool
 	)
 
 	flag.BoolVar(&verbose, "v", true, "verbose info log")
@@ -267,6 +487,8 @@ func main() {
 	flag.StringVar(&vScrnDefFile, "f", "", "virtual-screen-def.json file Path")
 	flag.IntVar(&keyNodeId, "N", -1, "search ula-node param by node_id from VScrnDef file")
 	flag.StringVar(&keyHostName, "H", "", "search ula-node param by hostname from VScrnDef file")
+	flag.StringVar(&stateFile, "s", "", "state file Path to save the last applied layout (default: state_file in VScrnDef file)")
+	flag.BoolVar(&restore, "r", false, "restore the last applied layout to the compositor on startup")
 
 	flag.Parse()
 
@@ -280,9 +502,9 @@ func main() {
 
 	DLog.Printf("ARG0:%s, ARG1:%s, ARG2:%s", flag.Arg(0), flag.Arg(1), flag.Arg(2))
 
-	vscrnDef, err := ula.ReadVScrnDef(vScrnDefFile)
+	vscrnDef, err := ula.ValidateVScrnDef(vScrnDefFile)
 	if err != nil {
-		ELog.Println("ReadVScrnDef error : ", err)
+		ELog.Printf("ValidateVScrnDef error :\n%s\n", err)
 		return
 	}
 
@@ -344,6 +566,12 @@ func main() {
 		return
 	}
 
+	defStateFile, restoreLayout := vscrnDef.GetStateFile(nodeId)
+	if stateFile == "" {
+		stateFile = defStateFile
+	}
+	restoreLayout = restoreLayout || restore
+
 	prefix := "ula-node-" + strconv.Itoa(nodeId)
 	SetLogPrefix(prefix)
 	DLog.Println(listenIp, ":", listenPort)
@@ -365,17 +593,21 @@ func main() {
 	if rvgpuwinmgr.IsRvgpuCompositor(vscrnDef, nodeId) {
 		plugin = rvgpuwinmgr.RvgpuPlugin{}
 	}
+	ulanode.SetTimeoutPolicy(vscrnDef.GetTimeoutPolicy(nodeId))
 	go plugin.Start(reqChan, respChan)
 
-	mainLoop(listener, nodeId, reqChan, respChan, plugin)
+	startDebugServer(vscrnDef, nodeId)
+
+	statusFunc := newNodeStatusFunc(vscrnDef, nodeId, getPluginType(plugin))
+	mainLoop(listener, nodeId, reqChan, respChan, plugin, stateFile, restoreLayout, statusFunc)
 }
 
 //export StartUlanode
 func StartUlanode(vScrnDefFile string, keyNodeId int, keyHostName string, keyIpAddr string) {
 
-	vscrnDef, err := ula.ReadVScrnDef(vScrnDefFile)
+	vscrnDef, err := ula.ValidateVScrnDef(vScrnDefFile)
 	if err != nil {
-		ELog.Println("ReadVScrnDef fail", err)
+		ELog.Printf("ValidateVScrnDef fail :\n%s\n", err)
 		return
 	}
 	var (
@@ -414,7 +646,12 @@ func StartUlanode(vScrnDefFile string, keyNodeId int, keyHostName string, keyIpA
 	if rvgpuwinmgr.IsRvgpuCompositor(vscrnDef, nodeId) {
 		plugin = rvgpuwinmgr.RvgpuPlugin{}
 	}
+	ulanode.SetTimeoutPolicy(vscrnDef.GetTimeoutPolicy(nodeId))
 	go plugin.Start(reqChan, respChan)
 
-	mainLoop(listener, nodeId, reqChan, respChan, plugin)
+	startDebugServer(vscrnDef, nodeId)
+
+	stateFile, restoreLayout := vscrnDef.GetStateFile(nodeId)
+	statusFunc := newNodeStatusFunc(vscrnDef, nodeId, getPluginType(plugin))
+	mainLoop(listener, nodeId, reqChan, respChan, plugin, stateFile, restoreLayout, statusFunc)
 }
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sort"
+	"time"
+	"ula-tools/internal/ula"
+	"ula-tools/internal/ula-node"
+	"ula-tools/internal/ula-node/rvgpuwinmgr"
+)
+
+func getPluginType(plugin ulanode.LocalCommandGenerator) string {
+	switch plugin.(type) {
+	case rvgpuwinmgr.RvgpuPlugin:
+		return "rvgpu"
+	default:
+		return "ivi"
+	}
+}
+
+/*
+ * returns the function which builds the status of the node. The compositor
+ * which has no rdisplay_ids shows all real displays of the node.
+ */
+func newNodeStatusFunc(vscrnDef *ula.VScrnDef, nodeId int, pluginType string) func() *ula.NodeStatus {
+	startTime := time.Now()
+
+	nodeRDisplayIds := make([]int, 0)
+	for _, rdisplay := range vscrnDef.RealDisplays {
+		if rdisplay.NodeId == nodeId {
+			nodeRDisplayIds = append(nodeRDisplayIds, rdisplay.RDisplayId)
+		}
+	}
+
+	return func() *ula.NodeStatus {
+		status := &ula.NodeStatus{
+			NodeId:     nodeId,
+			PluginType: pluginType,
+			Generation: ulanode.GetAppliedGeneration(),
+			UptimeSec:  int64(time.Since(startTime).Seconds()),
+			RDisplays:  make([]ula.RDisplayStatus, 0),
+		}
+
+		for _, comp := range ulanode.GetCompositorStatuses() {
+			rdisplayIds := comp.RDisplayIds
+			if rdisplayIds == nil {
+				rdisplayIds = nodeRDisplayIds
+			}
+			for _, rdisplayId := range rdisplayIds {
+				status.RDisplays = append(status.RDisplays, ula.RDisplayStatus{
+					RDisplayId:       rdisplayId,
+					Connected:        comp.Connected,
+					Reconnects:       comp.Reconnects,
+					LastResyncResult: comp.LastResyncResult,
+				})
+			}
+		}
+		sort.Slice(status.RDisplays, func(i, j int) bool {
+			return status.RDisplays[i].RDisplayId < status.RDisplays[j].RDisplayId
+		})
+
+		return status
+	}
+}
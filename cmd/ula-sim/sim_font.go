@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+/* 5x7 bitmap font for the labels of PNG, bit 4 is the left column */
+var simFont = map[rune][7]uint8{
+	'#': {0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a},
+	'(': {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')': {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	',': {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	'-': {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	'/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'0': {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1': {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3': {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4': {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5': {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6': {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9': {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	':': {0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00},
+	'=': {0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00},
+	'?': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'A': {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'B': {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C': {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D': {0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c},
+	'E': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G': {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H': {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I': {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M': {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P': {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q': {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R': {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S': {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T': {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X': {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x0a, 0x04, 0x04, 0x04, 0x04},
+	'Z': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	'_': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+}
+
+const (
+	SIM_FONT_W = 5
+	SIM_FONT_H = 7
+
+	/* the cell of a character including the spacing */
+	SIM_CELL_W = 6
+	SIM_CELL_H = 9
+)
+
+/* lowercase letters are drawn as uppercase, and the unknown characters as '?' */
+func simGlyph(c rune) [7]uint8 {
+	if c >= 'a' && c <= 'z' {
+		c = c - 'a' + 'A'
+	}
+	if c == ' ' {
+		return [7]uint8{}
+	}
+	glyph, ok := simFont[c]
+	if !ok {
+		return simFont['?']
+	}
+	return glyph
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.RGBA, alpha float64) {
+	if alpha <= 0 {
+		return
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+	a := uint8(alpha * 255)
+	/* image.Uniform takes the premultiplied color */
+	src := image.NewUniform(color.RGBA{
+		R: uint8(uint32(c.R) * uint32(a) / 255),
+		G: uint8(uint32(c.G) * uint32(a) / 255),
+		B: uint8(uint32(c.B) * uint32(a) / 255),
+		A: a,
+	})
+	draw.Draw(img, image.Rect(x, y, x+w, y+h), src, image.Point{}, draw.Over)
+}
+
+func strokeRect(img *image.RGBA, x, y, w, h int, c color.RGBA, width int, dashed bool) {
+	dash := 4 * width
+	line := func(x0, y0, length int, horizontal bool) {
+		for i := 0; i < length; i += dash {
+			if dashed && (i/dash)%2 == 1 {
+				continue
+			}
+			seg := dash
+			if i+seg > length {
+				seg = length - i
+			}
+			if horizontal {
+				fillRect(img, x0+i, y0, seg, width, c, 1)
+			} else {
+				fillRect(img, x0, y0+i, width, seg, c, 1)
+			}
+		}
+	}
+	line(x, y, w, true)
+	line(x, y+h-width, w, true)
+	line(x, y, h, false)
+	line(x+w-width, y, h, false)
+}
+
+func drawText(img *image.RGBA, x, y int, unit int, c color.RGBA, text string) {
+	for _, ch := range text {
+		glyph := simGlyph(ch)
+		for row := 0; row < SIM_FONT_H; row++ {
+			for col := 0; col < SIM_FONT_W; col++ {
+				if glyph[row]&(1<<uint(SIM_FONT_W-1-col)) == 0 {
+					continue
+				}
+				fillRect(img, x+col*unit, y+row*unit, unit, unit, c, 1)
+			}
+		}
+		x += SIM_CELL_W * unit
+	}
+}
+
+func writeScenePNG(path string, scene *simScene) error {
+	img := image.NewRGBA(image.Rect(0, 0, scene.Width, scene.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(simBackground), image.Point{}, draw.Src)
+
+	unit := scene.Unit
+	lineH := SIM_CELL_H * unit
+	pad := 2 * unit
+	for _, rect := range scene.Rects {
+		fillRect(img, rect.X, rect.Y, rect.W, rect.H, rect.Color, rect.Fill)
+		strokeRect(img, rect.X, rect.Y, rect.W, rect.H, rect.Color, unit, rect.Dashed)
+
+		for i, label := range rect.Labels {
+			y := rect.Y + pad + lineH*i
+			if rect.LabelBottom {
+				y = rect.Y + rect.H - pad - lineH*(len(rect.Labels)-i)
+			}
+			drawText(img, rect.X+pad, y+unit, unit, rect.Color, label)
+		}
+	}
+
+	captionW := len([]rune(scene.Caption)) * SIM_CELL_W * unit
+	drawText(img, scene.Width-pad-captionW, pad+unit, unit, simCaption, scene.Caption)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
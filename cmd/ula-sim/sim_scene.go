@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"ula-tools/internal/ula"
+)
+
+var (
+	simBackground  = color.RGBA{0x20, 0x20, 0x20, 0xff}
+	simHidden      = color.RGBA{0x9e, 0x9e, 0x9e, 0xff}
+	simSafetyArea  = color.RGBA{0xe1, 0x57, 0x59, 0xff}
+	simCaption     = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	simLayerColors = []color.RGBA{
+		{0x4e, 0x79, 0xa7, 0xff}, {0xf2, 0x8e, 0x2b, 0xff}, {0x59, 0xa1, 0x4f, 0xff}, {0xb0, 0x7a, 0xa1, 0xff},
+		{0x76, 0xb7, 0xb2, 0xff}, {0xed, 0xc9, 0x48, 0xff}, {0x9c, 0x75, 0x5f, 0xff}, {0xff, 0x9d, 0xa7, 0xff},
+	}
+)
+
+/* a rectangle to be drawn in the pixel coordinate of the real display */
+type simRect struct {
+	X int
+	Y int
+	W int
+	H int
+
+	Color  color.RGBA
+	Fill   float64 /* alpha of the fill, 0 for no fill */
+	Dashed bool
+
+	Labels      []string
+	LabelBottom bool
+}
+
+type simScene struct {
+	Width  int
+	Height int
+
+	/* the size of a pixel of the font */
+	Unit int
+
+	Caption string
+	Rects   []simRect
+}
+
+/* the destination of the surface is in the coordinate of the layer source, so it is scaled to the layer destination */
+func surfaceToScreen(player *ula.PixelLayer, psurf *ula.PixelSurface) (int, int, int, int) {
+	if player.PsrcW <= 0 || player.PsrcH <= 0 {
+		return psurf.PdstX, psurf.PdstY, psurf.PdstW, psurf.PdstH
+	}
+	x := player.PdstX + (psurf.PdstX-player.PsrcX)*player.PdstW/player.PsrcW
+	y := player.PdstY + (psurf.PdstY-player.PsrcY)*player.PdstH/player.PsrcH
+	w := psurf.PdstW * player.PdstW / player.PsrcW
+	h := psurf.PdstH * player.PdstH / player.PsrcH
+	return x, y, w, h
+}
+
+/* the surface is cut by the layer destination as the compositor does */
+func clipRect(x, y, w, h int, player *ula.PixelLayer) (int, int, int, int) {
+	x0, y0 := x, y
+	x1, y1 := x+w, y+h
+	if x0 < player.PdstX {
+		x0 = player.PdstX
+	}
+	if y0 < player.PdstY {
+		y0 = player.PdstY
+	}
+	if x1 > player.PdstX+player.PdstW {
+		x1 = player.PdstX + player.PdstW
+	}
+	if y1 > player.PdstY+player.PdstH {
+		y1 = player.PdstY + player.PdstH
+	}
+	return x0, y0, x1 - x0, y1 - y0
+}
+
+func visibilityLabels(visibility int, opacity float64) []string {
+	labels := make([]string, 0)
+	if visibility == 0 {
+		labels = append(labels, "hidden")
+	}
+	if opacity < 1.0 {
+		labels = append(labels, fmt.Sprintf("opacity %.2f", opacity))
+	}
+	return labels
+}
+
+/* the layers are drawn from the bottom, and each surface above its layer */
+func newSimScene(pscrn *ula.PixelScreen) *simScene {
+	rdisp := pscrn.Rdisplay
+	unit := rdisp.PixelW
+	if rdisp.PixelH < unit {
+		unit = rdisp.PixelH
+	}
+	unit = unit / 360
+	if unit < 1 {
+		unit = 1
+	}
+
+	scene := &simScene{
+		Width:   rdisp.PixelW,
+		Height:  rdisp.PixelH,
+		Unit:    unit,
+		Caption: fmt.Sprintf("node %d rdisplay %d vdisplay %d %dx%d %s", rdisp.NodeId, rdisp.RDisplayId, rdisp.VDisplayId, rdisp.PixelW, rdisp.PixelH, ula.NormalizeTransform(rdisp.Transform)),
+		Rects:   make([]simRect, 0),
+	}
+
+	for i, player := range pscrn.Players {
+		layerColor := simLayerColors[i%len(simLayerColors)]
+		layerHidden := player.Visibility == 0
+
+		rect := simRect{
+			X:           player.PdstX,
+			Y:           player.PdstY,
+			W:           player.PdstW,
+			H:           player.PdstH,
+			Color:       layerColor,
+			Fill:        0.2 * player.Opacity,
+			Labels:      append([]string{fmt.Sprintf("layer %d %s", player.VID, player.AppName)}, visibilityLabels(player.Visibility, player.Opacity)...),
+			LabelBottom: true,
+		}
+		if layerHidden {
+			rect.Color = simHidden
+			rect.Fill = 0
+			rect.Dashed = true
+		}
+		scene.Rects = append(scene.Rects, rect)
+
+		for _, psurf := range player.Psurfaces {
+			x, y, w, h := surfaceToScreen(&player, &psurf)
+			x, y, w, h = clipRect(x, y, w, h, &player)
+			if w <= 0 || h <= 0 {
+				continue
+			}
+			rect := simRect{
+				X:      x,
+				Y:      y,
+				W:      w,
+				H:      h,
+				Color:  layerColor,
+				Fill:   0.4 * player.Opacity * psurf.Opacity,
+				Labels: append([]string{fmt.Sprintf("surface %d", psurf.VID)}, visibilityLabels(psurf.Visibility, psurf.Opacity)...),
+			}
+			/* the surface of a hidden layer is not shown either */
+			if layerHidden || psurf.Visibility == 0 {
+				rect.Color = simHidden
+				rect.Fill = 0
+				rect.Dashed = true
+			}
+			scene.Rects = append(scene.Rects, rect)
+		}
+	}
+
+	for _, area := range pscrn.PsafetyAreas {
+		scene.Rects = append(scene.Rects, simRect{
+			X:           area.PixelX,
+			Y:           area.PixelY,
+			W:           area.PixelW,
+			H:           area.PixelH,
+			Color:       simSafetyArea,
+			Fill:        0.15,
+			Dashed:      true,
+			Labels:      []string{"safety area"},
+			LabelBottom: true,
+		})
+	}
+
+	return scene
+}
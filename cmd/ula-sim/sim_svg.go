@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"io/ioutil"
+)
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func writeSceneSVG(path string, scene *simScene) error {
+	fontSize := SIM_CELL_H * scene.Unit
+	pad := 2 * scene.Unit
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		scene.Width, scene.Height, scene.Width, scene.Height)
+	fmt.Fprintf(&buf, "  <title>%s</title>\n", html.EscapeString(scene.Caption))
+	fmt.Fprintf(&buf, "  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", scene.Width, scene.Height, svgColor(simBackground))
+
+	for _, rect := range scene.Rects {
+		style := fmt.Sprintf("fill=\"%s\" fill-opacity=\"%.2f\" stroke=\"%s\" stroke-width=\"%d\"",
+			svgColor(rect.Color), rect.Fill, svgColor(rect.Color), scene.Unit)
+		if rect.Dashed {
+			style += fmt.Sprintf(" stroke-dasharray=\"%d,%d\"", 4*scene.Unit, 4*scene.Unit)
+		}
+		fmt.Fprintf(&buf, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" %s/>\n", rect.X, rect.Y, rect.W, rect.H, style)
+
+		for i, label := range rect.Labels {
+			y := rect.Y + pad + fontSize*(i+1)
+			if rect.LabelBottom {
+				y = rect.Y + rect.H - pad - fontSize*(len(rect.Labels)-1-i)
+			}
+			fmt.Fprintf(&buf, "  <text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" fill=\"%s\">%s</text>\n",
+				rect.X+pad, y, fontSize, svgColor(rect.Color), html.EscapeString(label))
+		}
+	}
+
+	fmt.Fprintf(&buf, "  <text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" fill=\"%s\" text-anchor=\"end\">%s</text>\n",
+		scene.Width-pad, pad+fontSize, fontSize, svgColor(simCaption), html.EscapeString(scene.Caption))
+	fmt.Fprintln(&buf, "</svg>")
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"ula-tools/internal/ula"
+	"ula-tools/internal/ula-client/readclusterapp"
+	"ula-tools/internal/ula-client/ulacommgen"
+	"ula-tools/internal/ula-client/ulavscreen"
+	. "ula-tools/internal/ulog"
+)
+
+func printUsage() {
+	usage := `
+Usage: ula-sim [options] vsdPath layoutPath [layoutCommandPath ...]
+  vsdPath            virtual-screen-def.json
+  layoutPath         initial_vscreen.json, or the directory which has dwm_initial_layout.json
+                     of each application (the same as DWMPATH)
+  layoutCommandPath  layout commands applied after layoutPath in order
+Options:
+  -o      output directory (default: .)
+  -f      image format: png, svg or both (default: png)
+  -h      Show this message
+`
+	fmt.Println(usage)
+}
+
+/* a directory is read in the same way as DwmSetSystemLayout */
+func readLayoutCommand(layoutPath string) (string, error) {
+	info, err := os.Stat(layoutPath)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		calayoutTree, err := readclusterapp.ReadCALayoutTreeFromDir(layoutPath)
+		if err != nil {
+			return "", err
+		}
+		return ulacommgen.GenerateUlaCommInitialVscreen(calayoutTree)
+	}
+
+	jsonBytes, err := ioutil.ReadFile(layoutPath)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
+
+func applyLayoutCommand(vscrn *ulavscreen.VirtualScreen, layoutPath string) error {
+	command, err := readLayoutCommand(layoutPath)
+	if err != nil {
+		return err
+	}
+
+	var applyCommand map[string]interface{}
+	err = json.Unmarshal([]byte(command), &applyCommand)
+	if err != nil {
+		return err
+	}
+	if _, ok := applyCommand["command"].(string); !ok {
+		return errors.New("\"command\" is not specified")
+	}
+
+	_, err = vscrn.ApplyCommand(applyCommand)
+	return err
+}
+
+func writeScene(outDir string, format string, pscrn *ula.PixelScreen) ([]string, error) {
+	scene := newSimScene(pscrn)
+	base := filepath.Join(outDir, fmt.Sprintf("node%d-rdisplay%d", pscrn.Rdisplay.NodeId, pscrn.Rdisplay.RDisplayId))
+
+	paths := make([]string, 0)
+	if format == "png" || format == "both" {
+		err := writeScenePNG(base+".png", scene)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, base+".png")
+	}
+	if format == "svg" || format == "both" {
+		err := writeSceneSVG(base+".svg", scene)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, base+".svg")
+	}
+	return paths, nil
+}
+
+func main() {
+	var outDir string
+	var format string
+	var showHelp bool
+	flag.StringVar(&outDir, "o", ".", "output directory")
+	flag.StringVar(&format, "f", "png", "image format: png, svg or both")
+	flag.BoolVar(&showHelp, "h", false, "Show this message")
+	flag.Parse()
+	args := flag.Args()
+
+	if showHelp {
+		printUsage()
+		os.Exit(0)
+	}
+	if len(args) < 2 {
+		ELog.Printf("ula-sim requires arguments: vsdPath layoutPath")
+		printUsage()
+		os.Exit(2)
+	}
+	if format != "png" && format != "svg" && format != "both" {
+		ELog.Printf("invalid format: %s", format)
+		os.Exit(2)
+	}
+
+	vscrnDef, err := ula.ValidateVScrnDef(args[0])
+	if err != nil {
+		ELog.Printf("Failed to Validate VirtualScreen:\n%s\n", err)
+		os.Exit(1)
+	}
+	vscrn, err := ulavscreen.NewVirtualScreen(vscrnDef)
+	if err != nil {
+		ELog.Printf("Failed to Create VirtualScreen: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, layoutPath := range args[1:] {
+		err = applyLayoutCommand(vscrn, layoutPath)
+		if err != nil {
+			ELog.Printf("Failed to apply %s: %s\n", layoutPath, err)
+			os.Exit(1)
+		}
+	}
+
+	err = os.MkdirAll(outDir, 0755)
+	if err != nil {
+		ELog.Printf("Failed to create %s: %s\n", outDir, err)
+		os.Exit(1)
+	}
+
+	for _, nodeId := range vscrn.GetNodeIds() {
+		npscrns, err := ulavscreen.GenNodePixelScreens(vscrn, nodeId)
+		if err != nil {
+			ELog.Printf("Failed to convert the layout of node %d: %s\n", nodeId, err)
+			os.Exit(1)
+		}
+
+		pscrns := npscrns.Pscreens
+		sort.Slice(pscrns, func(i, j int) bool {
+			return pscrns[i].Rdisplay.RDisplayId < pscrns[j].Rdisplay.RDisplayId
+		})
+		for i := range pscrns {
+			paths, err := writeScene(outDir, format, &pscrns[i])
+			if err != nil {
+				ELog.Printf("Failed to write the image: %s\n", err)
+				os.Exit(1)
+			}
+			for _, path := range paths {
+				fmt.Printf("wrote %s\n", path)
+			}
+		}
+	}
+}
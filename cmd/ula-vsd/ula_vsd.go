@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"ula-tools/internal/ula"
+	. "ula-tools/internal/ulog"
+)
+
+func printUsage() {
+	usage := `
+Usage: ula-vsd <command> [options] [args]
+Commands:
+  validate    vsdPath ...
+              check the definitions strictly and print the problems with their lines
+  show        vsdPath
+              print the nodes, vdisplays, rdisplays, compositors and ports as tables
+  diff        vsdPath1 vsdPath2
+              print the differences between two definitions
+  render      [-o svgPath] [-w width] vsdPath
+              draw the virtual screen, virtual displays and safety areas to an SVG file
+              (default: vsdPath with the extension ".svg", width 1280)
+  -h          Show this message
+`
+	fmt.Println(usage)
+}
+
+/* the definition is only decoded, so show, diff and render can be used for a broken one */
+func readVScrnDef(vsdPath string) *ula.VScrnDef {
+	vscrnDef, err := ula.ReadVScrnDef(vsdPath)
+	if err != nil {
+		ELog.Printf("Failed to Read %s:\n%s\n", vsdPath, err)
+		os.Exit(2)
+	}
+	return vscrnDef
+}
+
+func validate(args []string) int {
+	if len(args) < 1 {
+		ELog.Printf("validate requires an argument: vsdPath")
+		return 2
+	}
+
+	ret := 0
+	for _, vsdPath := range args {
+		_, err := ula.ValidateVScrnDef(vsdPath)
+		if err != nil {
+			fmt.Println(err)
+			ret = 1
+			continue
+		}
+		fmt.Printf("%s: OK\n", vsdPath)
+	}
+	return ret
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" {
+		printUsage()
+		os.Exit(0)
+	}
+	command := os.Args[1]
+	args := os.Args[2:]
+
+	switch command {
+	case "validate":
+		os.Exit(validate(args))
+	case "show":
+		if len(args) != 1 {
+			ELog.Printf("show requires an argument: vsdPath")
+			os.Exit(2)
+		}
+		showVScrnDef(os.Stdout, readVScrnDef(args[0]))
+	case "diff":
+		if len(args) != 2 {
+			ELog.Printf("diff requires two arguments: vsdPath1 vsdPath2")
+			os.Exit(2)
+		}
+		diffs := diffVScrnDef(readVScrnDef(args[0]), readVScrnDef(args[1]))
+		for _, diff := range diffs {
+			fmt.Println(diff)
+		}
+		if len(diffs) > 0 {
+			os.Exit(1)
+		}
+	case "render":
+		var svgPath string
+		var width int
+		flags := flag.NewFlagSet("render", flag.ExitOnError)
+		flags.StringVar(&svgPath, "o", "", "SVG file to write")
+		flags.IntVar(&width, "w", 1280, "width of the SVG image")
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			ELog.Printf("render requires an argument: vsdPath")
+			os.Exit(2)
+		}
+		vsdPath := flags.Arg(0)
+		if svgPath == "" {
+			svgPath = strings.TrimSuffix(vsdPath, filepath.Ext(vsdPath)) + ".svg"
+		}
+		err := renderVScrnDefFile(svgPath, readVScrnDef(vsdPath), width)
+		if err != nil {
+			ELog.Printf("Failed to render %s: %v", svgPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", svgPath)
+	default:
+		ELog.Printf("unknown command: %s", command)
+		printUsage()
+		os.Exit(2)
+	}
+}
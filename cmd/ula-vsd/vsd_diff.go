@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"ula-tools/internal/ula"
+)
+
+/* the id fields which identify an element of the arrays, the index is used if it has none of them */
+var vsdIdFields = [][]string{
+	{"node_id", "rdisplay_id"},
+	{"vdisplay_id"},
+	{"node_id"},
+}
+
+func elementKey(elem interface{}, index int) string {
+	obj, ok := elem.(map[string]interface{})
+	if ok {
+		for _, fields := range vsdIdFields {
+			keys := make([]string, 0)
+			for _, field := range fields {
+				value, ok := obj[field]
+				if !ok {
+					break
+				}
+				keys = append(keys, fmt.Sprintf("%s=%v", field, value))
+			}
+			if len(keys) == len(fields) {
+				return strings.Join(keys, ",")
+			}
+		}
+	}
+	return fmt.Sprint(index)
+}
+
+func isScalarArray(array []interface{}) bool {
+	for _, elem := range array {
+		switch elem.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+/* flattens the JSON value to the leaves keyed by their paths */
+func flattenJson(path string, value interface{}, leaves map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenJson(childPath, child, leaves)
+		}
+	case []interface{}:
+		if isScalarArray(v) {
+			jsonBytes, _ := json.Marshal(v)
+			leaves[path] = string(jsonBytes)
+			return
+		}
+		for i, elem := range v {
+			key := elementKey(elem, i)
+			/* the definition may have duplicate ids */
+			childPath := fmt.Sprintf("%s[%s]", path, key)
+			for n := 2; ; n++ {
+				if _, ok := leaves[childPath]; !ok && !hasPrefixPath(leaves, childPath) {
+					break
+				}
+				childPath = fmt.Sprintf("%s[%s#%d]", path, key, n)
+			}
+			flattenJson(childPath, elem, leaves)
+		}
+	default:
+		jsonBytes, _ := json.Marshal(v)
+		leaves[path] = string(jsonBytes)
+	}
+}
+
+func hasPrefixPath(leaves map[string]string, path string) bool {
+	for leafPath := range leaves {
+		if strings.HasPrefix(leafPath, path+".") || strings.HasPrefix(leafPath, path+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenVScrnDef(vdef *ula.VScrnDef) map[string]string {
+	leaves := make(map[string]string)
+	jsonBytes, err := json.Marshal(vdef)
+	if err != nil {
+		return leaves
+	}
+	var value interface{}
+	err = json.Unmarshal(jsonBytes, &value)
+	if err != nil {
+		return leaves
+	}
+	flattenJson("", value, leaves)
+	return leaves
+}
+
+/*
+ * Returns the differences sorted by the path, "-" for removed, "+" for added
+ * and "~" for changed values. The elements of the arrays are matched by their
+ * ids, so reordering them is not reported.
+ */
+func diffVScrnDef(vdef1 *ula.VScrnDef, vdef2 *ula.VScrnDef) []string {
+	leaves1 := flattenVScrnDef(vdef1)
+	leaves2 := flattenVScrnDef(vdef2)
+
+	paths := make([]string, 0)
+	for path := range leaves1 {
+		paths = append(paths, path)
+	}
+	for path := range leaves2 {
+		if _, ok := leaves1[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]string, 0)
+	for _, path := range paths {
+		value1, ok1 := leaves1[path]
+		value2, ok2 := leaves2[path]
+		switch {
+		case !ok2:
+			diffs = append(diffs, fmt.Sprintf("- %s: %s", path, value1))
+		case !ok1:
+			diffs = append(diffs, fmt.Sprintf("+ %s: %s", path, value2))
+		case value1 != value2:
+			diffs = append(diffs, fmt.Sprintf("~ %s: %s -> %s", path, value1, value2))
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"ula-tools/internal/ula"
+)
+
+var vdisplayColors = []string{"#4e79a7", "#f28e2b", "#59a14f", "#b07aa1", "#76b7b2", "#edc948", "#9c755f", "#ff9da7"}
+
+func svgRect(buf *bytes.Buffer, x, y, w, h int, style string) {
+	fmt.Fprintf(buf, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" vector-effect=\"non-scaling-stroke\" %s/>\n", x, y, w, h, style)
+}
+
+func svgText(buf *bytes.Buffer, x, y int, fontSize int, style string, text string) {
+	fmt.Fprintf(buf, "  <text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"%d\" %s>%s</text>\n",
+		x, y, fontSize, style, html.EscapeString(text))
+}
+
+/* the SVG is drawn in the virtual coordinate, and scaled to the width by viewBox */
+func renderVScrnDef(vdef *ula.VScrnDef, width int) ([]byte, error) {
+	size := vdef.Def2D.Size
+	if size.VirtualW <= 0 || size.VirtualH <= 0 {
+		return nil, errors.New(fmt.Sprintf("zero-sized virtual screen (%dx%d)", size.VirtualW, size.VirtualH))
+	}
+	if width <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid width: %d", width))
+	}
+	height := width * size.VirtualH / size.VirtualW
+	fontSize := size.VirtualW / 80
+	if size.VirtualH/40 < fontSize {
+		fontSize = size.VirtualH / 40
+	}
+	if fontSize < 1 {
+		fontSize = 1
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, size.VirtualW, size.VirtualH)
+	svgRect(&buf, 0, 0, size.VirtualW, size.VirtualH, "fill=\"#f4f4f4\" stroke=\"#000000\" stroke-width=\"2\"")
+
+	for i, vdisp := range vdef.Def2D.VirtualDisplays {
+		color := vdisplayColors[i%len(vdisplayColors)]
+		svgRect(&buf, vdisp.VirtualX, vdisp.VirtualY, vdisp.VirtualW, vdisp.VirtualH,
+			fmt.Sprintf("fill=\"%s\" fill-opacity=\"0.25\" stroke=\"%s\" stroke-width=\"2\"", color, color))
+
+		lines := []string{
+			fmt.Sprintf("%s (vdisplay %d)", vdisp.DispName, vdisp.VDisplayId),
+			fmt.Sprintf("%d,%d %dx%d", vdisp.VirtualX, vdisp.VirtualY, vdisp.VirtualW, vdisp.VirtualH),
+		}
+		for _, rdisp := range vdef.RealDisplays {
+			if rdisp.VDisplayId != vdisp.VDisplayId {
+				continue
+			}
+			line := fmt.Sprintf("node %d rdisplay %d: %dx%d", rdisp.NodeId, rdisp.RDisplayId, rdisp.PixelW, rdisp.PixelH)
+			if ula.NormalizeTransform(rdisp.Transform) != ula.TRANSFORM_NORMAL {
+				line += " " + rdisp.Transform
+			}
+			lines = append(lines, line)
+		}
+		for j, line := range lines {
+			svgText(&buf, vdisp.VirtualX+fontSize/2, vdisp.VirtualY+fontSize*(j+1)+fontSize/2, fontSize, "fill=\"#000000\"", line)
+		}
+	}
+
+	for _, area := range vdef.VirtualSafetyArea {
+		svgRect(&buf, area.VirtualX, area.VirtualY, area.VirtualW, area.VirtualH,
+			"fill=\"#e15759\" fill-opacity=\"0.15\" stroke=\"#e15759\" stroke-width=\"2\" stroke-dasharray=\"6,4\"")
+		svgText(&buf, area.VirtualX+fontSize/2, area.VirtualY+area.VirtualH-fontSize/2, fontSize, "fill=\"#e15759\"",
+			fmt.Sprintf("safety area %d,%d %dx%d", area.VirtualX, area.VirtualY, area.VirtualW, area.VirtualH))
+	}
+
+	svgText(&buf, fontSize/2, size.VirtualH-fontSize/2, fontSize, "fill=\"#606060\"",
+		fmt.Sprintf("virtual screen %dx%d", size.VirtualW, size.VirtualH))
+	fmt.Fprintln(&buf, "</svg>")
+
+	return buf.Bytes(), nil
+}
+
+func renderVScrnDefFile(svgPath string, vdef *ula.VScrnDef, width int) error {
+	svgBytes, err := renderVScrnDef(vdef, width)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(svgPath, svgBytes, 0644)
+}
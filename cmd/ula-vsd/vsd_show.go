@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"ula-tools/internal/ula"
+)
+
+func joinInts(values []int) string {
+	strs := make([]string, 0)
+	for _, value := range values {
+		strs = append(strs, strconv.Itoa(value))
+	}
+	return strings.Join(strs, ",")
+}
+
+func printTable(w io.Writer, title string, header string, rows []string) {
+	fmt.Fprintf(w, "%s:\n", title)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  "+header)
+	for _, row := range rows {
+		fmt.Fprintln(tw, "  "+row)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
+func showVScrnDef(w io.Writer, vdef *ula.VScrnDef) {
+	fmt.Fprintf(w, "virtual screen: %dx%d\n\n", vdef.Def2D.Size.VirtualW, vdef.Def2D.Size.VirtualH)
+
+	rows := make([]string, 0)
+	for _, node := range vdef.Nodes {
+		rows = append(rows, fmt.Sprintf("%d\t%s\t%s", node.NodeId, node.HostName, node.Ip))
+	}
+	printTable(w, "nodes", "NODE\tHOSTNAME\tIP", rows)
+
+	rows = make([]string, 0)
+	for _, vdisp := range vdef.Def2D.VirtualDisplays {
+		rows = append(rows, fmt.Sprintf("%d\t%s\t%d,%d\t%dx%d",
+			vdisp.VDisplayId, vdisp.DispName, vdisp.VirtualX, vdisp.VirtualY, vdisp.VirtualW, vdisp.VirtualH))
+	}
+	printTable(w, "virtual displays", "VDISPLAY\tNAME\tPOSITION\tSIZE", rows)
+
+	rows = make([]string, 0)
+	for _, rdisp := range vdef.RealDisplays {
+		rows = append(rows, fmt.Sprintf("%d\t%d\t%d\t%dx%d\t%s",
+			rdisp.NodeId, rdisp.RDisplayId, rdisp.VDisplayId, rdisp.PixelW, rdisp.PixelH, ula.NormalizeTransform(rdisp.Transform)))
+	}
+	printTable(w, "real displays", "NODE\tRDISPLAY\tVDISPLAY\tPIXEL SIZE\tTRANSFORM", rows)
+
+	rows = make([]string, 0)
+	for _, fwn := range vdef.DistributedWindowSystem.FrameworkNode {
+		for _, com := range fwn.Compositor {
+			rows = append(rows, fmt.Sprintf("%d\t%s\t%s", fwn.NodeId, joinInts(com.VDisplayIds), com.SockDomainName))
+		}
+	}
+	printTable(w, "compositors", "NODE\tVDISPLAYS\tSOCKET", rows)
+
+	dws := vdef.DistributedWindowSystem
+	rows = []string{fmt.Sprintf("%d\tula-client-manager\t%d", dws.ULAClientManager.NodeId, dws.ULAClientManager.Port)}
+	for _, fwn := range dws.FrameworkNode {
+		rows = append(rows, fmt.Sprintf("%d\tula-node\t%d", fwn.NodeId, fwn.Ula.Port))
+		if fwn.Ula.Debug {
+			rows = append(rows, fmt.Sprintf("%d\tula-node debug\t%d", fwn.NodeId, fwn.Ula.DebugPort))
+		}
+	}
+	printTable(w, "ports", "NODE\tSERVICE\tPORT", rows)
+
+	if len(vdef.VirtualSafetyArea) > 0 {
+		rows = make([]string, 0)
+		for _, area := range vdef.VirtualSafetyArea {
+			rows = append(rows, fmt.Sprintf("%d,%d\t%dx%d", area.VirtualX, area.VirtualY, area.VirtualW, area.VirtualH))
+		}
+		printTable(w, "safety areas", "POSITION\tSIZE", rows)
+	}
+}
@@ -31,6 +31,7 @@ type CAVsurface struct {
 	VdstW      int
 	VdstH      int
 	Visibility *int
+	Opacity    *float64
 }
 
 type CAVlayer struct {
@@ -50,6 +51,7 @@ type CAVlayer struct {
 	VdstW      int
 	VdstH      int
 	Visibility *int
+	Opacity    *float64
 	Vsurfaces  []CAVsurface
 }
 
@@ -22,6 +22,8 @@ import (
 	"errors"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
@@ -142,28 +144,173 @@ func DwmClientInit() (*grpc.ClientConn, error) {
 func DwmClientSetSystemLayout(client dwm.DwmServiceClient, ctx context.Context) error {
 	resp, err := client.DwmSetSystemLayout(ctx, &dwm.Empty{})
 	if err != nil {
+		logErrorResponse(err)
 		return err
 	}
-	ILog.Println("DwmClientSetSystemLayout DwmSetSystemLayout:", resp.GetStatus())
+	ILog.Println("DwmClientSetSystemLayout DwmSetSystemLayout:", resp.GetStatus(), "request_id:", resp.GetRequestId())
+	logNodeResults(resp)
 	return nil
 }
 
-func DwmClientSetLayoutCommand(client dwm.DwmServiceClient, ctx context.Context, layoutCommandFilePath string) error {
+func readLayoutCommandFile(layoutCommandFilePath string) (string, error) {
 	f, err := os.Open(layoutCommandFilePath)
 	if err != nil {
-		return err
+		return "", err
 	}
+	defer f.Close()
+
 	layoutCommandBytes, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return string(layoutCommandBytes), nil
+}
+
+func DwmClientSetLayoutCommand(client dwm.DwmServiceClient, ctx context.Context, layoutCommandFilePath string) error {
+	layoutCommand, err := readLayoutCommandFile(layoutCommandFilePath)
 	if err != nil {
 		return err
 	}
 	commReq := &dwm.SetLayoutCommandRequest{
-		LayoutCommand: string(layoutCommandBytes),
+		LayoutCommand: layoutCommand,
 	}
 	resp, err := client.DwmSetLayoutCommand(ctx, commReq)
 	if err != nil {
+		logErrorResponse(err)
 		return err
 	}
-	ILog.Println("DwmSetLayoutCommand response:", resp.GetStatus())
+	ILog.Println("DwmSetLayoutCommand response:", resp.GetStatus(), "request_id:", resp.GetRequestId())
+	logNodeResults(resp)
 	return nil
 }
+
+/* returns the commands which the nodes and the compositors would receive, nothing is applied */
+func DwmClientDryRunLayoutCommand(client dwm.DwmServiceClient, ctx context.Context, layoutCommandFilePath string) (*dwm.Response, error) {
+	layoutCommand, err := readLayoutCommandFile(layoutCommandFilePath)
+	if err != nil {
+		return nil, err
+	}
+	commReq := &dwm.SetLayoutCommandRequest{
+		LayoutCommand: layoutCommand,
+		DryRun:        true,
+	}
+	resp, err := client.DwmSetLayoutCommand(ctx, commReq)
+	if err != nil {
+		logErrorResponse(err)
+		return nil, err
+	}
+	ILog.Println("DwmSetLayoutCommand response:", resp.GetStatus(), "request_id:", resp.GetRequestId())
+	return resp, nil
+}
+
+func DwmClientUndoLayout(client dwm.DwmServiceClient, ctx context.Context) error {
+	resp, err := client.DwmUndoLayout(ctx, &dwm.UndoLayoutRequest{})
+	if err != nil {
+		logErrorResponse(err)
+		return err
+	}
+	ILog.Println("DwmUndoLayout response:", resp.GetStatus(), "request_id:", resp.GetRequestId())
+	logNodeResults(resp)
+	return nil
+}
+
+func DwmClientRevertToGeneration(client dwm.DwmServiceClient, ctx context.Context, generation uint64) error {
+	req := &dwm.RevertToGenerationRequest{
+		Generation: generation,
+	}
+	resp, err := client.DwmRevertToGeneration(ctx, req)
+	if err != nil {
+		logErrorResponse(err)
+		return err
+	}
+	ILog.Println("DwmRevertToGeneration response:", resp.GetStatus(), "request_id:", resp.GetRequestId())
+	logNodeResults(resp)
+	return nil
+}
+
+func logNodeResults(resp *dwm.Response) {
+	for _, nr := range resp.GetNodeResults() {
+		if nr.GetCode() == dwm.ResultCode_RESULT_OK {
+			DLog.Printf("node %d (%s): OK latency=%dus", nr.GetNodeId(), nr.GetTargetAddr(), nr.GetLatencyUs())
+			continue
+		}
+		WLog.Printf("node %d (%s): %s phase=%s result=%d error=%q timed_out=%t latency=%dus",
+			nr.GetNodeId(), nr.GetTargetAddr(), nr.GetCode(), nr.GetPhase(), nr.GetResult(), nr.GetError(), nr.GetTimedOut(), nr.GetLatencyUs())
+	}
+}
+
+/* the server attaches the Response to the gRPC status on failure */
+func logErrorResponse(err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+	for _, detail := range st.Details() {
+		resp, ok := detail.(*dwm.Response)
+		if !ok {
+			continue
+		}
+		ELog.Printf("%s: %s (%s) request_id: %s", resp.GetStatus(), resp.GetCode(), st.Code(), resp.GetRequestId())
+		logNodeResults(resp)
+	}
+}
+
+func DwmClientGetLayout(client dwm.DwmServiceClient, ctx context.Context, withNodePixelScreens bool, nodeIds []int32) (*dwm.GetLayoutResponse, error) {
+	req := &dwm.GetLayoutRequest{
+		WithNodePixelScreens: withNodePixelScreens,
+		NodeIds:              nodeIds,
+	}
+	resp, err := client.DwmGetLayout(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ILog.Println("DwmGetLayout response:", resp.GetStatus())
+	return resp, nil
+}
+
+func DwmClientGetClusterStatus(client dwm.DwmServiceClient, ctx context.Context) (*dwm.ClusterStatusResponse, error) {
+	resp, err := client.DwmGetClusterStatus(ctx, &dwm.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	ILog.Println("DwmGetClusterStatus response:", resp.GetStatus())
+	return resp, nil
+}
+
+/* calls handler for each layout event until ctx is done or the stream is closed */
+func DwmClientWatchLayout(client dwm.DwmServiceClient, ctx context.Context, handler func(*dwm.LayoutEvent)) error {
+	stream, err := client.DwmWatchLayout(ctx, &dwm.Empty{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		handler(event)
+	}
+}
+
+/* calls handler for each node state event until ctx is done or the stream is closed */
+func DwmClientWatchNodeState(client dwm.DwmServiceClient, ctx context.Context, handler func(*dwm.NodeStateEvent)) error {
+	stream, err := client.DwmWatchNodeState(ctx, &dwm.Empty{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		handler(event)
+	}
+}
@@ -19,17 +19,27 @@ package dwmapi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net"
 	"os"
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-client/readclusterapp"
 	"ula-tools/internal/ula-client/ulacommgen"
 	"ula-tools/internal/ula-client/ulamulticonn"
 	"ula-tools/internal/ula-client/ulavscreen"
+	"ula-tools/internal/ula-node"
+	"ula-tools/internal/ula-node/iviwinmgr"
+	"ula-tools/internal/ula-node/rvgpuwinmgr"
 	. "ula-tools/internal/ulog"
 	"ula-tools/proto/grpc/dwm"
 )
@@ -58,36 +68,470 @@ type server struct {
 	dwm.UnimplementedDwmServiceServer
 }
 
+var requestSeq uint64
+
+func genRequestId(requestId string) string {
+	if requestId != "" {
+		return requestId
+	}
+	seq := atomic.AddUint64(&requestSeq, 1)
+	return fmt.Sprintf("%x-%d", time.Now().UnixNano(), seq)
+}
+
+func convNodeResultCode(code ulamulticonn.NodeResultCode) dwm.ResultCode {
+	switch code {
+	case ulamulticonn.NODE_RESULT_OK:
+		return dwm.ResultCode_RESULT_OK
+	case ulamulticonn.NODE_RESULT_VALIDATION_ERROR:
+		return dwm.ResultCode_RESULT_VALIDATION_ERROR
+	case ulamulticonn.NODE_RESULT_UNREACHABLE:
+		return dwm.ResultCode_RESULT_NODE_UNREACHABLE
+	case ulamulticonn.NODE_RESULT_TIMEOUT:
+		return dwm.ResultCode_RESULT_NODE_TIMEOUT
+	case ulamulticonn.NODE_RESULT_COMPOSITOR_FAILURE:
+		return dwm.ResultCode_RESULT_COMPOSITOR_FAILURE
+	default:
+		return dwm.ResultCode_RESULT_INTERNAL_ERROR
+	}
+}
+
+func convNodeResults(nodeResults []ulamulticonn.NodeResult) []*dwm.NodeResult {
+	dnrs := make([]*dwm.NodeResult, 0)
+	for _, nr := range nodeResults {
+		dnr := &dwm.NodeResult{
+			NodeId:     int32(nr.NodeId),
+			TargetAddr: nr.TargetAddr,
+			Result:     int32(nr.Result),
+			Error:      nr.Error,
+			LatencyUs:  nr.Latency.Microseconds(),
+			TimedOut:   nr.TimedOut,
+			Code:       convNodeResultCode(nr.Code),
+			Phase:      nr.Phase,
+		}
+		dnrs = append(dnrs, dnr)
+	}
+	return dnrs
+}
+
+/* the code of the whole request is the most significant one among the nodes */
+func summarizeResultCode(dnrs []*dwm.NodeResult) dwm.ResultCode {
+	priorities := []dwm.ResultCode{
+		dwm.ResultCode_RESULT_VALIDATION_ERROR,
+		dwm.ResultCode_RESULT_NODE_UNREACHABLE,
+		dwm.ResultCode_RESULT_NODE_TIMEOUT,
+		dwm.ResultCode_RESULT_COMPOSITOR_FAILURE,
+		dwm.ResultCode_RESULT_INTERNAL_ERROR,
+	}
+	for _, code := range priorities {
+		for _, dnr := range dnrs {
+			if dnr.GetCode() == code {
+				return code
+			}
+		}
+	}
+	return dwm.ResultCode_RESULT_INTERNAL_ERROR
+}
+
+func convResultCode2GrpcCode(code dwm.ResultCode) codes.Code {
+	switch code {
+	case dwm.ResultCode_RESULT_OK:
+		return codes.OK
+	case dwm.ResultCode_RESULT_PARSE_ERROR, dwm.ResultCode_RESULT_VALIDATION_ERROR:
+		return codes.InvalidArgument
+	case dwm.ResultCode_RESULT_NODE_UNREACHABLE:
+		return codes.Unavailable
+	case dwm.ResultCode_RESULT_NODE_TIMEOUT:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+/* the Response is attached to the gRPC status as its detail */
+func genErrorStatus(resp *dwm.Response, err error) error {
+	st := status.New(convResultCode2GrpcCode(resp.Code), err.Error())
+	stWithDetails, derr := st.WithDetails(resp)
+	if derr != nil {
+		WLog.Println("status WithDetails error: ", derr)
+		return st.Err()
+	}
+	return stWithDetails.Err()
+}
+
+func checkLayoutCommand(layoutCommand string) error {
+	var mJson map[string]interface{}
+	err := json.Unmarshal([]byte(layoutCommand), &mJson)
+	if err != nil {
+		return err
+	}
+	if _, ok := mJson["command"].(string); !ok {
+		return errors.New("\"command\" is not specified")
+	}
+	return nil
+}
+
+func sendLayoutCommand(ctx context.Context, requestId string, layoutCommand string, funcName string, successStatus string) (*dwm.Response, error) {
+	resp := &dwm.Response{
+		RequestId: requestId,
+		Status:    "Failed to " + funcName,
+	}
+
+	err := checkLayoutCommand(layoutCommand)
+	if err != nil {
+		ELog.Println(err)
+		resp.Code = dwm.ResultCode_RESULT_PARSE_ERROR
+		return nil, genErrorStatus(resp, err)
+	}
+
+	nodeResults, err := ulamulticonn.UlaMulCon.SendLayoutCommand(ctx, layoutCommand)
+	return genNodeResultsResponse(resp, nodeResults, err, successStatus)
+}
+
+func convCompositorCommands(comms []ulanode.CompositorCommand) []*dwm.CompositorCommand {
+	dcomms := make([]*dwm.CompositorCommand, 0)
+	for _, comm := range comms {
+		rdisplayIds := make([]int32, 0)
+		for _, rdisplayId := range comm.RDisplayIds {
+			rdisplayIds = append(rdisplayIds, int32(rdisplayId))
+		}
+		dcomms = append(dcomms, &dwm.CompositorCommand{
+			Compositor:  comm.Compositor,
+			RdisplayIds: rdisplayIds,
+			Messages:    comm.Messages,
+		})
+	}
+	return dcomms
+}
+
+/* generates the commands of the nodes and the compositors for the plugin of each node */
+func genDryRunNodeResult(vscrnDef *ula.VScrnDef, nodeData ulavscreen.DryRunNodeData) (*dwm.DryRunNodeResult, error) {
+	jsonBytes, err := json.Marshal(nodeData.Acdata)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginType := "ivi"
+	var comms []ulanode.CompositorCommand
+	if rvgpuwinmgr.HasRvgpuCompositor(vscrnDef, nodeData.NodeId) {
+		pluginType = "rvgpu"
+		comms, err = rvgpuwinmgr.GenerateDryRunCommands(vscrnDef, nodeData.NodeId, nodeData.Acdata, nodeData.AppliedNPScreens)
+	} else {
+		comms, err = iviwinmgr.GenerateDryRunCommands(nodeData.Acdata, nodeData.AppliedNPScreens)
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("node %d: %s", nodeData.NodeId, err))
+	}
+
+	return &dwm.DryRunNodeResult{
+		NodeId:             int32(nodeData.NodeId),
+		PluginType:         pluginType,
+		ApplyCommandData:   string(jsonBytes),
+		CompositorCommands: convCompositorCommands(comms),
+	}, nil
+}
+
+/* runs the layout command without sending it to the nodes nor changing VScreen */
+func dryRunLayoutCommand(requestId string, layoutCommand string, funcName string) (*dwm.Response, error) {
+	resp := &dwm.Response{
+		RequestId: requestId,
+		Status:    "Failed to " + funcName + " (dry run)",
+	}
+
+	err := checkLayoutCommand(layoutCommand)
+	if err != nil {
+		ELog.Println(err)
+		resp.Code = dwm.ResultCode_RESULT_PARSE_ERROR
+		return nil, genErrorStatus(resp, err)
+	}
+
+	nodeDatas, err := ulavscreen.DryRunVScreen(layoutCommand)
+	if err != nil {
+		ELog.Println(requestId, err)
+		resp.Code = dwm.ResultCode_RESULT_VALIDATION_ERROR
+		return nil, genErrorStatus(resp, err)
+	}
+
+	vscrnDef := ulavscreen.GetVScreen().VScrnDef
+	for _, nodeData := range nodeDatas {
+		result, err := genDryRunNodeResult(&vscrnDef, nodeData)
+		if err != nil {
+			ELog.Println(requestId, err)
+			resp.Code = dwm.ResultCode_RESULT_INTERNAL_ERROR
+			return nil, genErrorStatus(resp, err)
+		}
+		resp.DryRunResults = append(resp.DryRunResults, result)
+	}
+
+	resp.Status = "Dry run of " + funcName + " successfully"
+	resp.Succeeded = true
+	resp.Code = dwm.ResultCode_RESULT_OK
+	return resp, nil
+}
+
+func genNodeResultsResponse(resp *dwm.Response, nodeResults []ulamulticonn.NodeResult, err error, successStatus string) (*dwm.Response, error) {
+	requestId := resp.RequestId
+	resp.NodeResults = convNodeResults(nodeResults)
+	if err != nil {
+		ELog.Println(requestId, err)
+		var prepareErr *ulamulticonn.PrepareError
+		if errors.As(err, &prepareErr) {
+			resp.Code = dwm.ResultCode_RESULT_VALIDATION_ERROR
+		} else {
+			resp.Code = summarizeResultCode(resp.NodeResults)
+		}
+		return nil, genErrorStatus(resp, err)
+	}
+
+	resp.Status = successStatus
+	resp.Succeeded = true
+	resp.Code = dwm.ResultCode_RESULT_OK
+	return resp, nil
+}
+
 func (s *server) DwmSetSystemLayout(ctx context.Context, req *dwm.Empty) (*dwm.Response, error) {
 	logFunc()
+	requestId := genRequestId("")
 
 	calayoutTree, err := readclusterapp.ReadCALayoutTreeFromCfg()
 	if err != nil {
-		return &dwm.Response{Status: "Failed to DwmSetSystemLayout"}, err
+		resp := &dwm.Response{
+			RequestId: requestId,
+			Status:    "Failed to DwmSetSystemLayout",
+			Code:      dwm.ResultCode_RESULT_PARSE_ERROR,
+		}
+		return nil, genErrorStatus(resp, err)
 	}
 
 	var layoutComm string
 	layoutComm, err = ulacommgen.GenerateUlaCommInitialVscreen(calayoutTree)
 	if err != nil {
-		return &dwm.Response{Status: "Failed to DwmSetSystemLayout"}, err
+		resp := &dwm.Response{
+			RequestId: requestId,
+			Status:    "Failed to DwmSetSystemLayout",
+			Code:      dwm.ResultCode_RESULT_INTERNAL_ERROR,
+		}
+		return nil, genErrorStatus(resp, err)
 	}
 
-	err = ulamulticonn.UlaMulCon.SendLayoutCommand(layoutComm)
-	if err != nil {
-		return &dwm.Response{Status: "Failed to DwmSetSystemLayout"}, err
-	}
-	return &dwm.Response{Status: "System layout set successfully"}, nil
+	return sendLayoutCommand(ctx, requestId, layoutComm, "DwmSetSystemLayout", "System layout set successfully")
 }
 
 func (s *server) DwmSetLayoutCommand(ctx context.Context, req *dwm.SetLayoutCommandRequest) (*dwm.Response, error) {
 	logFunc()
+	requestId := genRequestId(req.GetRequestId())
 	layoutCommand := req.GetLayoutCommand()
-	err := ulamulticonn.UlaMulCon.SendLayoutCommand(layoutCommand)
+
+	if req.GetDryRun() {
+		return dryRunLayoutCommand(requestId, layoutCommand, "DwmSetLayoutCommand")
+	}
+	return sendLayoutCommand(ctx, requestId, layoutCommand, "DwmSetLayoutCommand", "Set layout command successfully")
+}
+
+/* re-applies the layout of the generation in the history to all nodes as a new generation */
+func revertLayout(ctx context.Context, requestId string, generation uint64, funcName string) (*dwm.Response, error) {
+	resp := &dwm.Response{
+		RequestId: requestId,
+		Status:    "Failed to " + funcName,
+	}
+
+	nodeResults, err := ulamulticonn.UlaMulCon.SendRevertCommand(ctx, generation)
+	return genNodeResultsResponse(resp, nodeResults, err, fmt.Sprintf("Reverted to generation %d successfully", generation))
+}
+
+func (s *server) DwmUndoLayout(ctx context.Context, req *dwm.UndoLayoutRequest) (*dwm.Response, error) {
+	logFunc()
+	requestId := genRequestId(req.GetRequestId())
+
+	generation, err := ulavscreen.GetUndoGeneration()
 	if err != nil {
-		ELog.Println(err)
-		return &dwm.Response{Status: "Failed to DwmSetLayoutCommand"}, err
+		ELog.Println(requestId, err)
+		resp := &dwm.Response{
+			RequestId: requestId,
+			Status:    "Failed to DwmUndoLayout",
+			Code:      dwm.ResultCode_RESULT_VALIDATION_ERROR,
+		}
+		return nil, genErrorStatus(resp, err)
+	}
+
+	return revertLayout(ctx, requestId, generation, "DwmUndoLayout")
+}
+
+func (s *server) DwmRevertToGeneration(ctx context.Context, req *dwm.RevertToGenerationRequest) (*dwm.Response, error) {
+	logFunc()
+	requestId := genRequestId(req.GetRequestId())
+
+	return revertLayout(ctx, requestId, req.GetGeneration(), "DwmRevertToGeneration")
+}
+
+func (s *server) DwmGetLayout(ctx context.Context, req *dwm.GetLayoutRequest) (*dwm.GetLayoutResponse, error) {
+	logFunc()
+
+	/* the generation is read first, so the layout is never older than it */
+	generation := ulavscreen.GetLayoutGeneration()
+	vscrn := ulavscreen.GetVScreen()
+	if vscrn == nil {
+		return nil, status.Error(codes.FailedPrecondition, "VirtualScreen is not initialized")
+	}
+
+	layout, err := ulacommgen.GenerateUlaCommVscreenLayout(vscrn)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &dwm.GetLayoutResponse{
+		Status:           "Get layout successfully",
+		Layout:           layout,
+		NodePixelScreens: make([]*dwm.NodePixelScreens, 0),
+		Generation:       generation,
+	}
+
+	if !req.GetWithNodePixelScreens() {
+		return resp, nil
+	}
+
+	nodeIds := vscrn.GetNodeIds()
+	if len(req.GetNodeIds()) != 0 {
+		nodeIds = make([]int, 0)
+		for _, nodeId := range req.GetNodeIds() {
+			nodeIds = append(nodeIds, int(nodeId))
+		}
+	}
+
+	for _, nodeId := range nodeIds {
+		npscreens, err := ulavscreen.GenNodePixelScreens(vscrn, nodeId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("node %d: %s", nodeId, err))
+		}
+		jsonBytes, err := json.Marshal(npscreens)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.NodePixelScreens = append(resp.NodePixelScreens, &dwm.NodePixelScreens{
+			NodeId:       int32(nodeId),
+			PixelScreens: string(jsonBytes),
+		})
+	}
+
+	return resp, nil
+}
+
+func convNodeStatus(nr ulamulticonn.NodeResult) *dwm.NodeStatus {
+	dns := &dwm.NodeStatus{
+		NodeId:     int32(nr.NodeId),
+		TargetAddr: nr.TargetAddr,
+		Reachable:  nr.Code == ulamulticonn.NODE_RESULT_OK && nr.Status != nil,
+		Error:      nr.Error,
+		Rdisplays:  make([]*dwm.RDisplayStatus, 0),
+	}
+	if nr.Status == nil {
+		return dns
+	}
+
+	dns.PluginType = nr.Status.PluginType
+	dns.Generation = nr.Status.Generation
+	dns.UptimeSec = nr.Status.UptimeSec
+	for _, rdisplay := range nr.Status.RDisplays {
+		dns.Rdisplays = append(dns.Rdisplays, &dwm.RDisplayStatus{
+			RdisplayId:       int32(rdisplay.RDisplayId),
+			Connected:        rdisplay.Connected,
+			Reconnects:       int32(rdisplay.Reconnects),
+			LastResyncResult: int32(rdisplay.LastResyncResult),
+		})
+	}
+	return dns
+}
+
+/* the unreachable nodes are included in the response, so this never fails */
+func (s *server) DwmGetClusterStatus(ctx context.Context, req *dwm.Empty) (*dwm.ClusterStatusResponse, error) {
+	logFunc()
+
+	resp := &dwm.ClusterStatusResponse{
+		Status:     "Get cluster status successfully",
+		Generation: ulavscreen.GetLayoutGeneration(),
+		Nodes:      make([]*dwm.NodeStatus, 0),
+	}
+
+	for _, nr := range ulamulticonn.UlaMulCon.GetNodeStatuses(ctx) {
+		resp.Nodes = append(resp.Nodes, convNodeStatus(nr))
+	}
+
+	return resp, nil
+}
+
+func (s *server) DwmWatchLayout(req *dwm.Empty, stream dwm.DwmService_DwmWatchLayoutServer) error {
+	logFunc()
+
+	id, eventChan := ulavscreen.SubscribeLayoutEvents()
+	defer ulavscreen.UnsubscribeLayoutEvents(id)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			DLog.Println("DwmWatchLayout finished: ", stream.Context().Err())
+			return nil
+		case event, ok := <-eventChan:
+			if !ok {
+				WLog.Println("DwmWatchLayout: the client is too slow, the stream is closed")
+				return status.Error(codes.ResourceExhausted, "layout events overflowed, read the layout again and re-subscribe")
+			}
+			devent := &dwm.LayoutEvent{
+				Generation: event.Generation,
+				Command:    event.Command,
+				ChgIds:     make([]*dwm.IdPair, 0),
+			}
+			for _, idPair := range event.ChgIds {
+				devent.ChgIds = append(devent.ChgIds, &dwm.IdPair{
+					LayerId:   int32(idPair.LayerId),
+					SurfaceId: int32(idPair.SurfaceId),
+				})
+			}
+			err := stream.Send(devent)
+			if err != nil {
+				WLog.Println("DwmWatchLayout Send error: ", err)
+				return err
+			}
+		}
+	}
+}
+
+func convNodeState(state ulamulticonn.NodeState) *dwm.NodeStateEvent {
+	return &dwm.NodeStateEvent{
+		NodeId:      int32(state.NodeId),
+		TargetAddr:  state.TargetAddr,
+		Up:          state.Up,
+		Reason:      state.Reason,
+		SinceUnixMs: state.Since.UnixMilli(),
+	}
+}
+
+/* the current states of all nodes are sent first, and then each change of them */
+func (s *server) DwmWatchNodeState(req *dwm.Empty, stream dwm.DwmService_DwmWatchNodeStateServer) error {
+	logFunc()
+
+	id, eventChan := ulamulticonn.SubscribeNodeStateEvents()
+	defer ulamulticonn.UnsubscribeNodeStateEvents(id)
+
+	for _, state := range ulamulticonn.UlaMulCon.GetNodeStates() {
+		err := stream.Send(convNodeState(state))
+		if err != nil {
+			WLog.Println("DwmWatchNodeState Send error: ", err)
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			DLog.Println("DwmWatchNodeState finished: ", stream.Context().Err())
+			return nil
+		case state := <-eventChan:
+			err := stream.Send(convNodeState(state))
+			if err != nil {
+				WLog.Println("DwmWatchNodeState Send error: ", err)
+				return err
+			}
+		}
 	}
-	return &dwm.Response{Status: "Set layout command successfully"}, nil
 }
 
 func getServerAddr(vscrnDef *ula.VScrnDef) string {
@@ -120,9 +564,9 @@ func getServerAddr(vscrnDef *ula.VScrnDef) string {
 }
 
 func DwmServerInit(vsdPath string) error {
-	vscrnDef, err := ula.ReadVScrnDef(vsdPath)
+	vscrnDef, err := ula.ValidateVScrnDef(vsdPath)
 	if err != nil {
-		ELog.Printf("Failed to Read VirtualScreen: %s\n", err)
+		ELog.Printf("Failed to Validate VirtualScreen:\n%s\n", err)
 		return err
 	}
 	ulavscreen.VScreen, err = ulavscreen.NewVirtualScreen(vscrnDef)
@@ -133,7 +577,7 @@ func DwmServerInit(vsdPath string) error {
 	force := ula.GetEnvBool("ULA_FORCE", false)
 	err = ulamulticonn.UlaConnectionInit(force)
 	if err != nil {
-		ELog.Println("Failed to Init Connection: %s\n", err)
+		ELog.Printf("Failed to Init Connection: %s\n", err)
 		return err
 	}
 
@@ -33,18 +33,19 @@ const (
 )
 
 type caCfgInitialLayoutVsurface struct {
-	VID        int  `json:"VID"`
-	PixelW     int  `json:"pixel_w"`
-	PixelH     int  `json:"pixel_h"`
-	PsrcX      int  `json:"psrc_x"`
-	PsrcY      int  `json:"psrc_y"`
-	PsrcW      int  `json:"psrc_w"`
-	PsrcH      int  `json:"psrc_h"`
-	VdstX      int  `json:"vdst_x"`
-	VdstY      int  `json:"vdst_y"`
-	VdstW      int  `json:"vdst_w"`
-	VdstH      int  `json:"vdst_h"`
-	Visibility *int `json:"visibility"`
+	VID        int      `json:"VID"`
+	PixelW     int      `json:"pixel_w"`
+	PixelH     int      `json:"pixel_h"`
+	PsrcX      int      `json:"psrc_x"`
+	PsrcY      int      `json:"psrc_y"`
+	PsrcW      int      `json:"psrc_w"`
+	PsrcH      int      `json:"psrc_h"`
+	VdstX      int      `json:"vdst_x"`
+	VdstY      int      `json:"vdst_y"`
+	VdstW      int      `json:"vdst_w"`
+	VdstH      int      `json:"vdst_h"`
+	Visibility *int     `json:"visibility"`
+	Opacity    *float64 `json:"opacity"`
 }
 
 type caCfgInitialLayoutVLayer struct {
@@ -63,6 +64,7 @@ type caCfgInitialLayoutVLayer struct {
 	VdstW      int                          `json:"vdst_w"`
 	VdstH      int                          `json:"vdst_h"`
 	Visibility *int                         `json:"visibility"`
+	Opacity    *float64                     `json:"opacity"`
 	Vsurface   []caCfgInitialLayoutVsurface `json:"vsurface"`
 }
 
@@ -71,9 +73,8 @@ type caCfgInitialLayout struct {
 	Vlayer  []caCfgInitialLayoutVLayer `json:"vlayer"`
 }
 
-func getDwmClusterAppDirs() []string {
+func getDwmClusterAppDirs(dwmPath string) []string {
 	var paths []string
-	dwmPath := ula.GetEnvString("DWMPATH", DEF_DWM_DIR)
 	files, err := ioutil.ReadDir(dwmPath)
 	if err != nil {
 		return paths
@@ -163,6 +164,7 @@ func newCAVLayoutFromCfg(appDir string) (*core.CALayout, error) {
 			VdstW:      r.VdstW,
 			VdstH:      r.VdstH,
 			Visibility: r.Visibility,
+			Opacity:    r.Opacity,
 			Vsurfaces:  make([]core.CAVsurface, 0),
 		}
 		for _, s := range r.Vsurface {
@@ -180,6 +182,7 @@ func newCAVLayoutFromCfg(appDir string) (*core.CALayout, error) {
 				VdstW:      s.VdstW,
 				VdstH:      s.VdstH,
 				Visibility: s.Visibility,
+				Opacity:    s.Opacity,
 			}
 
 			vlayer.Vsurfaces = append(vlayer.Vsurfaces, vsurf)
@@ -219,12 +222,17 @@ func insertCAVLayoutToCALayoutTree(tree *core.CALayoutTree, nlayout *core.CALayo
 }
 
 func ReadCALayoutTreeFromCfg() (*core.CALayoutTree, error) {
+	return ReadCALayoutTreeFromDir(ula.GetEnvString("DWMPATH", DEF_DWM_DIR))
+}
+
+/* reads dwm_initial_layout.json of each application directory in dwmPath */
+func ReadCALayoutTreeFromDir(dwmPath string) (*core.CALayoutTree, error) {
 
 	calayoutTree := &core.CALayoutTree{
 		Vlayers: make([]core.CAVlayer, 0),
 	}
 
-	appDirs := getDwmClusterAppDirs()
+	appDirs := getDwmClusterAppDirs(dwmPath)
 
 	DLog.Println(appDirs)
 
@@ -19,8 +19,10 @@ package ulacommgen
 
 import (
 	"encoding/json"
+	"sort"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-client/core"
+	"ula-tools/internal/ula-client/ulavscreen"
 	. "ula-tools/internal/ulog"
 )
 
@@ -46,6 +48,7 @@ func convCAVSurface2UPIVsurface(csurf *core.CAVsurface, appName string) *UPIVsur
 	usurf.VdstH = csurf.VdstH
 
 	usurf.Visibility = csurf.Visibility
+	usurf.Opacity = csurf.Opacity
 	return usurf
 }
 
@@ -58,6 +61,7 @@ func convCAVlayer2UPIVlayer(clayer *core.CAVlayer) *UPIVlayer {
 	ulayer.VID = clayer.VID
 	ulayer.Coord = clayer.Coord
 	ulayer.VdisplayId = clayer.VdisplayId
+	ulayer.ZOrder = clayer.ZOrder
 
 	ulayer.VirtualW = clayer.VirtualW
 	ulayer.VirtualH = clayer.VirtualH
@@ -73,6 +77,7 @@ func convCAVlayer2UPIVlayer(clayer *core.CAVlayer) *UPIVlayer {
 	ulayer.VdstH = clayer.VdstH
 
 	ulayer.Visibility = clayer.Visibility
+	ulayer.Opacity = clayer.Opacity
 
 	ulayer.Surface = make([]UPIVsurface, 0)
 
@@ -119,9 +124,126 @@ func convVirtualSurface2UPIVsurface(vsurf *ula.VirtualSurface, appName string) *
 	usurf.VdstH = vsurf.VdstH
 
 	usurf.Visibility = &vsurf.Visibility
+	usurf.Opacity = &vsurf.Opacity
 	return usurf
 }
 
+func convVirtualLayer2UPIVlayer(vlayer *ula.VirtualLayer, zorder int) *UPIVlayer {
+	ulayer := new(UPIVlayer)
+
+	ulayer.AppName = vlayer.AppName
+
+	ulayer.VID = vlayer.VID
+	if vlayer.Coord == ula.COORD_VDISPLAY {
+		ulayer.Coord = "vdisplay"
+	} else {
+		ulayer.Coord = "global"
+	}
+	ulayer.VdisplayId = vlayer.VDisplayId
+	ulayer.ZOrder = zorder
+
+	ulayer.VirtualW = vlayer.VirtualW
+	ulayer.VirtualH = vlayer.VirtualH
+
+	ulayer.VsrcX = vlayer.VsrcX
+	ulayer.VsrcY = vlayer.VsrcY
+	ulayer.VsrcW = vlayer.VsrcW
+	ulayer.VsrcH = vlayer.VsrcH
+
+	ulayer.VdstX = vlayer.VdstX
+	ulayer.VdstY = vlayer.VdstY
+	ulayer.VdstW = vlayer.VdstW
+	ulayer.VdstH = vlayer.VdstH
+
+	visibility := vlayer.Visibility
+	ulayer.Visibility = &visibility
+	opacity := vlayer.Opacity
+	ulayer.Opacity = &opacity
+
+	ulayer.Surface = make([]UPIVsurface, 0)
+
+	for _, vsurf := range vlayer.Vsurfaces {
+		usurf := convVirtualSurface2UPIVsurface(&vsurf, ulayer.AppName)
+		ulayer.Surface = append(ulayer.Surface, *usurf)
+	}
+
+	return ulayer
+}
+
+func convVirtualScreen2UPIVscreenLayout(vscrn *ulavscreen.VirtualScreen) *UPIVscreenLayout {
+
+	ulayout := &UPIVscreenLayout{
+		Command:    "initial_vscreen",
+		VirtualW:   vscrn.VirtualWidth,
+		VirtualH:   vscrn.VirtualHeight,
+		Vdisplay:   make([]UPIVdisplay, 0),
+		Layer:      make([]UPIVlayer, 0),
+		SafetyArea: make([]UPIVsafetyArea, 0),
+	}
+
+	vdspIds := make([]int, 0)
+	for vdspid := range vscrn.VirtualDisplays {
+		vdspIds = append(vdspIds, vdspid)
+	}
+	sort.Ints(vdspIds)
+
+	/* every virtual display has the same layers, the union is taken just in case */
+	layerMap := make(map[int]bool)
+	for _, vdspid := range vdspIds {
+		vdisp := vscrn.VirtualDisplays[vdspid]
+		udisp := UPIVdisplay{
+			VDisplayId: vdisp.VDisplayId,
+			DispName:   vdisp.DispName,
+			VirtualX:   vdisp.VirtualX,
+			VirtualY:   vdisp.VirtualY,
+			VirtualW:   vdisp.VirtualW,
+			VirtualH:   vdisp.VirtualH,
+			VlayerIds:  make([]int, 0),
+		}
+		for _, vlayer := range vscrn.VdispVlayers[vdspid] {
+			udisp.VlayerIds = append(udisp.VlayerIds, vlayer.VID)
+			if layerMap[vlayer.VID] {
+				continue
+			}
+			layerMap[vlayer.VID] = true
+			ulayer := convVirtualLayer2UPIVlayer(&vlayer, len(ulayout.Layer))
+			ulayout.Layer = append(ulayout.Layer, *ulayer)
+		}
+		ulayout.Vdisplay = append(ulayout.Vdisplay, udisp)
+	}
+
+	if len(vdspIds) != 0 {
+		for _, vsarea := range vscrn.VdispVsafetyAreas[vdspIds[0]] {
+			usarea := UPIVsafetyArea{
+				VirtualX: vsarea.VirtualX,
+				VirtualY: vsarea.VirtualY,
+				VirtualW: vsarea.VirtualW,
+				VirtualH: vsarea.VirtualH,
+			}
+			ulayout.SafetyArea = append(ulayout.SafetyArea, usarea)
+		}
+	}
+
+	return ulayout
+}
+
+/* generate the json of the current layout in the vocabulary of initial_vscreen */
+func GenerateUlaCommVscreenLayout(vscrn *ulavscreen.VirtualScreen) (string, error) {
+
+	var msg string
+	ulayout := convVirtualScreen2UPIVscreenLayout(vscrn)
+
+	jsonBytes, err := json.Marshal(ulayout)
+	if err != nil {
+		ELog.Println("JSON Marshal error: ", err)
+		return msg, err
+	}
+
+	msg = string(jsonBytes)
+
+	return msg, nil
+}
+
 func GenerateUlaCommInitialVscreen(ctree *core.CALayoutTree) (string, error) {
 
 	var msg string
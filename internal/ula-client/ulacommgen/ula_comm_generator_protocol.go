@@ -18,19 +18,20 @@
 package ulacommgen
 
 type UPIVsurface struct {
-	AppName    string `json:"appli_name"`
-	VID        int    `json:"VID"`
-	PixelW     int    `json:"pixel_w"`
-	PixelH     int    `json:"pixel_h"`
-	PsrcX      int    `json:"psrc_x"`
-	PsrcY      int    `json:"psrc_y"`
-	PsrcW      int    `json:"psrc_w"`
-	PsrcH      int    `json:"psrc_h"`
-	VdstX      int    `json:"vdst_x"`
-	VdstY      int    `json:"vdst_y"`
-	VdstW      int    `json:"vdst_w"`
-	VdstH      int    `json:"vdst_h"`
-	Visibility *int   `json:"visibility"`
+	AppName    string   `json:"appli_name"`
+	VID        int      `json:"VID"`
+	PixelW     int      `json:"pixel_w"`
+	PixelH     int      `json:"pixel_h"`
+	PsrcX      int      `json:"psrc_x"`
+	PsrcY      int      `json:"psrc_y"`
+	PsrcW      int      `json:"psrc_w"`
+	PsrcH      int      `json:"psrc_h"`
+	VdstX      int      `json:"vdst_x"`
+	VdstY      int      `json:"vdst_y"`
+	VdstW      int      `json:"vdst_w"`
+	VdstH      int      `json:"vdst_h"`
+	Visibility *int     `json:"visibility"`
+	Opacity    *float64 `json:"opacity"`
 }
 
 type UPIVlayer struct {
@@ -38,6 +39,7 @@ type UPIVlayer struct {
 	VID        int           `json:"VID"`
 	Coord      string        `json:"coord"`
 	VdisplayId int           `json:"vdisplay_id"`
+	ZOrder     int           `json:"z_order"`
 	VirtualW   int           `json:"virtual_w"`
 	VirtualH   int           `json:"virtual_h"`
 	VsrcX      int           `json:"vsrc_x"`
@@ -49,6 +51,7 @@ type UPIVlayer struct {
 	VdstW      int           `json:"vdst_w"`
 	VdstH      int           `json:"vdst_h"`
 	Visibility *int          `json:"visibility"`
+	Opacity    *float64      `json:"opacity"`
 	Surface    []UPIVsurface `json:"vsurface"`
 }
 
@@ -57,3 +60,30 @@ type UPIVscreen struct {
 	Layer   []UPIVlayer   `json:"vlayer"`
 	Surface []UPIVsurface `json:"vsurface"`
 }
+
+type UPIVdisplay struct {
+	VDisplayId int    `json:"vdisplay_id"`
+	DispName   string `json:"disp_name"`
+	VirtualX   int    `json:"virtual_x"`
+	VirtualY   int    `json:"virtual_y"`
+	VirtualW   int    `json:"virtual_w"`
+	VirtualH   int    `json:"virtual_h"`
+	VlayerIds  []int  `json:"vlayer_ids"`
+}
+
+type UPIVsafetyArea struct {
+	VirtualX int `json:"virtual_x"`
+	VirtualY int `json:"virtual_y"`
+	VirtualW int `json:"virtual_w"`
+	VirtualH int `json:"virtual_h"`
+}
+
+/* the current layout, which can also be used as initial_vscreen */
+type UPIVscreenLayout struct {
+	Command    string           `json:"command"`
+	VirtualW   int              `json:"virtual_w"`
+	VirtualH   int              `json:"virtual_h"`
+	Vdisplay   []UPIVdisplay    `json:"vdisplay"`
+	Layer      []UPIVlayer      `json:"vlayer"`
+	SafetyArea []UPIVsafetyArea `json:"safety_area"`
+}
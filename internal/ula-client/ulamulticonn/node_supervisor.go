@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulamulticonn
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+	"ula-tools/internal/ula"
+	. "ula-tools/internal/ulog"
+)
+
+const (
+	HEARTBEAT_INTERVAL         = 2 * time.Second
+	HEARTBEAT_MAX_MISSES       = 3
+	NODE_STATE_EVENT_CHAN_SIZE = 16
+)
+
+/* up/down state of the connection to a ula-node, also notified as an event on each change */
+type NodeState struct {
+	NodeId     int
+	TargetAddr string
+	Up         bool
+	Since      time.Time
+	Reason     string
+}
+
+/*
+ * supervision state of a node, indexed by chanId. sendMutex is held while a
+ * request is sent to the node and its response is waited for.
+ */
+type nodeSupervision struct {
+	state     NodeState
+	known     bool
+	conn      net.Conn
+	misses    int
+	backoff   ula.Backoff
+	sendMutex sync.Mutex
+}
+
+var (
+	nodeStateMutex        sync.Mutex
+	nodeStateSubscriberId int
+	nodeStateSubscribers  = make(map[int]chan NodeState)
+)
+
+func SubscribeNodeStateEvents() (int, chan NodeState) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	nodeStateSubscriberId++
+	eventChan := make(chan NodeState, NODE_STATE_EVENT_CHAN_SIZE)
+	nodeStateSubscribers[nodeStateSubscriberId] = eventChan
+
+	return nodeStateSubscriberId, eventChan
+}
+
+func UnsubscribeNodeStateEvents(id int) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	eventChan, ok := nodeStateSubscribers[id]
+	if !ok {
+		return
+	}
+	delete(nodeStateSubscribers, id)
+	close(eventChan)
+}
+
+/* returns the states of the nodes which have been checked at least once */
+func (ums *UlaMultiConnector) GetNodeStates() []NodeState {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	states := make([]NodeState, 0)
+	for i := range ums.supervisions {
+		if ums.supervisions[i].known {
+			states = append(states, ums.supervisions[i].state)
+		}
+	}
+	return states
+}
+
+/* records the state of the node, and logs and notifies it only if it is changed */
+func (ums *UlaMultiConnector) setNodeUp(chanId int, up bool, reason string) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	sv := &ums.supervisions[chanId]
+	if sv.known && sv.state.Up == up {
+		return
+	}
+	sv.known = true
+	sv.state = NodeState{
+		NodeId:     ums.targetNodeAddrs[chanId].NodeId,
+		TargetAddr: ums.targetNodeAddrs[chanId].TargetAddr,
+		Up:         up,
+		Since:      time.Now(),
+		Reason:     reason,
+	}
+
+	if up {
+		ILog.Printf("node %d (%s) is up: %s", sv.state.NodeId, sv.state.TargetAddr, reason)
+	} else {
+		WLog.Printf("node %d (%s) is down: %s", sv.state.NodeId, sv.state.TargetAddr, reason)
+	}
+
+	for id, eventChan := range nodeStateSubscribers {
+		select {
+		case eventChan <- sv.state:
+		default:
+			WLog.Printf("node state event subscriber %d is full, the event of node %d is dropped", id, sv.state.NodeId)
+		}
+	}
+}
+
+func (ums *UlaMultiConnector) setNodeConn(chanId int, conn net.Conn) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	ums.supervisions[chanId].conn = conn
+	ums.supervisions[chanId].misses = 0
+}
+
+/*
+ * Closes the connection and drops the channels of the node, so that its
+ * goroutine exits and reconnectNodes connects the node again. The sendMutex
+ * of the node must be held by the caller, so that nothing is sent on the
+ * closed channel.
+ */
+func (ums *UlaMultiConnector) closeNodeConn(chanId int) {
+	nodeStateMutex.Lock()
+	conn := ums.supervisions[chanId].conn
+	sendChan := ums.sendChans[chanId]
+	ums.supervisions[chanId].conn = nil
+	ums.sendChans[chanId] = nil
+	ums.respChans[chanId] = nil
+	nodeStateMutex.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+	if sendChan != nil {
+		close(sendChan)
+	}
+}
+
+/* reconnects the nodes which are not connected, each of them after its backoff interval */
+func (ums *UlaMultiConnector) reconnectNodes() {
+	chanIds := make([]int, 0)
+	for chanId := range ums.targetNodeAddrs {
+		nodeStateMutex.Lock()
+		isDue := ums.supervisions[chanId].backoff.IsDue()
+		nodeStateMutex.Unlock()
+		if ums.isConnected(chanId) || !isDue {
+			continue
+		}
+		chanIds = append(chanIds, chanId)
+	}
+
+	if len(chanIds) == 0 {
+		return
+	}
+	ums.connectTargets(chanIds)
+
+	for _, chanId := range chanIds {
+		isConnected := ums.isConnected(chanId)
+		nodeStateMutex.Lock()
+		if isConnected {
+			ums.supervisions[chanId].backoff.Reset()
+		} else {
+			ums.supervisions[chanId].backoff.Failed()
+		}
+		nodeStateMutex.Unlock()
+	}
+}
+
+/*
+ * Sends the heartbeat to the connected nodes. The node which misses it
+ * HEARTBEAT_MAX_MISSES times in a row is marked down and reconnected.
+ * The transactions are not waited for, and the nodes which are busy with
+ * them are skipped.
+ */
+func (ums *UlaMultiConnector) sendHeartbeats() {
+	heartbeatJson, _ := json.Marshal(map[string]string{"type": ula.REQUEST_TYPE_HEARTBEAT})
+
+	results := make(map[int]NodeResult)
+	commands := make(map[int]string)
+	for chanId := range ums.targetNodeAddrs {
+		sendChan, _ := ums.getNodeChans(chanId)
+		if sendChan == nil {
+			continue
+		}
+		/* the node is still stuck in the previous request, so sending another one would block */
+		if len(sendChan) != 0 {
+			results[chanId] = newNodeResult(ums.targetNodeAddrs[chanId], NODE_RESULT_TIMEOUT, -1, "previous request is pending")
+			continue
+		}
+		commands[chanId] = string(heartbeatJson)
+	}
+	if len(commands) != 0 {
+		for chanId, nr := range ums.sendPhase(context.Background(), ula.REQUEST_TYPE_HEARTBEAT, commands, true) {
+			results[chanId] = nr
+		}
+	}
+
+	for chanId, nr := range results {
+		switch nr.Code {
+		case NODE_RESULT_OK:
+			nodeStateMutex.Lock()
+			ums.supervisions[chanId].misses = 0
+			nodeStateMutex.Unlock()
+			ums.setNodeUp(chanId, true, "heartbeat ok")
+		case NODE_RESULT_TIMEOUT:
+			nodeStateMutex.Lock()
+			ums.supervisions[chanId].misses++
+			misses := ums.supervisions[chanId].misses
+			nodeStateMutex.Unlock()
+			if misses >= HEARTBEAT_MAX_MISSES {
+				ums.setNodeUp(chanId, false, "heartbeat timeout")
+				ums.supervisions[chanId].sendMutex.Lock()
+				ums.closeNodeConn(chanId)
+				ums.supervisions[chanId].sendMutex.Unlock()
+			}
+		case NODE_RESULT_UNREACHABLE:
+			/* the goroutine of the node has already updated its state */
+		default:
+			WLog.Printf("Heartbeat of node %d failed: %s", nr.NodeId, nr.Error)
+		}
+	}
+}
+
+/* runs until the process exits */
+func (ums *UlaMultiConnector) superviseNodes() {
+	ticker := time.NewTicker(HEARTBEAT_INTERVAL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ums.reconnectNodes()
+		ums.sendHeartbeats()
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"reflect"
 	"strconv"
 	"sync"
@@ -39,10 +40,15 @@ var UlaMulCon *UlaMultiConnector
 
 var MAGIC_CODE []byte = []byte{0x55, 0x4C, 0x41, 0x30} // 'ULA0' ascii code
 
+const NOT_CONNECTED string = "not connected"
+
 var Mutex struct {
 	sync.Mutex
 }
 
+/* serializes the connection setup, so that a node never gets two connections */
+var connectMutex sync.Mutex
+
 type TargetNodeAddr struct {
 	NodeId     int
 	TargetAddr string
@@ -50,20 +56,72 @@ type TargetNodeAddr struct {
 
 type UlaMultiConnector struct {
 	targetNodeAddrs []TargetNodeAddr
-	sendChans       []chan string
-	respChans       []chan UlaCommandResponse
-	force           bool
+
+	/* the channels to the goroutine of each node, guarded by nodeStateMutex */
+	sendChans []chan nodeRequest
+	respChans []chan NodeResult
+
+	force        bool
+	txId         uint64
+	policies     []ula.TimeoutPolicy
+	supervisions []nodeSupervision
+}
+
+/*
+ * a request to the goroutine of each node, Command is the ApplyCommandData for the node.
+ * The request times out if the response does not arrive by Deadline.
+ */
+type nodeRequest struct {
+	Phase    string
+	Command  string
+	Deadline time.Time
 }
 
 type UlaCommandResponse struct {
 	Type   string
 	Result int
+	Status *ula.NodeStatus
+	Seq    uint64
+}
+
+type NodeResultCode int
+
+const (
+	NODE_RESULT_OK NodeResultCode = iota
+	NODE_RESULT_VALIDATION_ERROR
+	NODE_RESULT_UNREACHABLE
+	NODE_RESULT_TIMEOUT
+	NODE_RESULT_COMPOSITOR_FAILURE
+	NODE_RESULT_INTERNAL_ERROR
+)
+
+/* result of a layout command on one ula-node */
+type NodeResult struct {
+	NodeId     int
+	TargetAddr string
+	Code       NodeResultCode
+	Phase      string
+	Result     int
+	Error      string
+	Latency    time.Duration
+	TimedOut   bool
+	Status     *ula.NodeStatus
+}
+
+/* the command was rejected by ula-client-manager itself, so it was not sent to any node */
+type PrepareError struct {
+	Err error
+}
+
+func (e *PrepareError) Error() string {
+	return fmt.Sprintf("apply command error: %s", e.Err)
 }
 
 type DistribNode struct {
 	NodeId int
 	Ip     string
 	Port   int
+	Policy ula.TimeoutPolicy
 }
 
 func newUlaMultiConn(force bool, vsdPath ...string) (*UlaMultiConnector, error) {
@@ -75,12 +133,14 @@ func newUlaMultiConn(force bool, vsdPath ...string) (*UlaMultiConnector, error)
 
 	targetNum := 0
 	var targets []TargetNodeAddr
+	var policies []ula.TimeoutPolicy
 	for _, d := range dNodes {
 		targetAddr := d.Ip + ":" + strconv.Itoa(d.Port)
 		targets = append(targets, TargetNodeAddr{
 			NodeId:     d.NodeId,
 			TargetAddr: targetAddr,
 		})
+		policies = append(policies, d.Policy)
 		targetNum++
 	}
 
@@ -93,8 +153,8 @@ func newUlaMultiConn(force bool, vsdPath ...string) (*UlaMultiConnector, error)
 		return nil, nil
 	}
 
-	sendChans := make([]chan string, len(targets))
-	respChans := make([]chan UlaCommandResponse, len(targets))
+	sendChans := make([]chan nodeRequest, len(targets))
+	respChans := make([]chan NodeResult, len(targets))
 	for i := range targets {
 		sendChans[i] = nil
 		respChans[i] = nil
@@ -106,176 +166,326 @@ func newUlaMultiConn(force bool, vsdPath ...string) (*UlaMultiConnector, error)
 		sendChans:       sendChans,
 		respChans:       respChans,
 		force:           force,
+		policies:        policies,
+		supervisions:    make([]nodeSupervision, len(targets)),
+	}
+	for i := range ulaMulCon.supervisions {
+		ulaMulCon.supervisions[i].backoff = policies[i].NewBackoff()
 	}
 	return ulaMulCon, nil
 }
 
-func retryConnectTarget(sockChan chan net.Conn, addr string) {
-	for {
-		conn, err := net.Dial("tcp", addr)
+/* each dial waits ConnectTimeout, and it is retried retryCount times with the backoff of the policy */
+func connectTarget(ctx context.Context, addr string, policy ula.TimeoutPolicy, retryCount int) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: policy.ConnectTimeout}
+	backoff := policy.NewBackoff()
+	for attempt := 0; ; attempt++ {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
 		if err == nil {
-			sockChan <- conn
-			break
+			ILog.Println("Dial connected to ", addr)
+			return conn, nil
+		}
+		if attempt >= retryCount {
+			return nil, errors.New(fmt.Sprintf("Dial cannot connect to master: %s", err))
 		}
 		DLog.Printf("Dial failed connect : %s retry\n", err)
-		time.Sleep(10 * time.Millisecond)
-	}
-}
-
-func connectTarget(addr string, timeout time.Duration) (net.Conn, error) {
-	conn, err := net.Dial("tcp", addr)
-	if err == nil {
-		ILog.Println("Dial connected to ", addr)
-		return conn, nil
-	}
-
-	if timeout > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
-		defer cancel()
-		sockChan := make(chan net.Conn, 1)
-		go retryConnectTarget(sockChan, addr)
 
+		backoff.Failed()
 		select {
 		case <-ctx.Done():
-			return nil, errors.New("Dial cannot connect to master")
-		case conn = <-sockChan:
-			ILog.Println("Retry Dial connected to ", addr)
-			return conn, nil
+			return nil, errors.New(fmt.Sprintf("Dial cannot connect to master: %s", ctx.Err()))
+		case <-time.After(backoff.Interval()):
 		}
 	}
+}
 
-	return nil, errors.New("Dial cannot connect to master")
+/* adds seq to the command, the ula-node returns it in the response */
+func addSeq(command string, seq uint64) (string, error) {
+	mJson := make(map[string]json.RawMessage)
+	err := json.Unmarshal([]byte(command), &mJson)
+	if err != nil {
+		return "", err
+	}
+	mJson["seq"] = json.RawMessage(strconv.FormatUint(seq, 10))
+	jsonBytes, err := json.Marshal(mJson)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
 }
 
-func sendCommand(conn net.Conn, command string) ([]byte, uint32, error) {
+/* written returns the number of bytes of the message which were written */
+func writeCommand(conn net.Conn, command string) (int, error) {
+	msg := make([]byte, 0, len(MAGIC_CODE)+4+len(command))
+	msg = append(msg, MAGIC_CODE...)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(command)))
+	msg = append(msg, command...)
 
-	n, err := conn.Write(MAGIC_CODE)
-	if err != nil || n == 0 {
-		ELog.Printf("Write MAGIC_CODE error: %s \n", err)
-		return nil, 0, err
+	DLog.Println("Write JSON size:", len(command))
+	written, err := conn.Write(msg)
+	if err != nil {
+		ELog.Printf("Write error: %s \n", err)
 	}
+	return written, err
+}
 
-	msgLen := uint32(len(command))
+/* read returns the number of bytes of the message which were read */
+func readResponse(conn net.Conn) ([]byte, int, error) {
 	size := make([]byte, 4)
-	binary.BigEndian.PutUint32(size, msgLen)
-
-	DLog.Println("Write JSON size:", msgLen)
-	n, err = conn.Write([]byte(size))
-	if err != nil || n == 0 {
-		ELog.Printf("Write DATA Size error: %s \n", err)
-		return nil, 0, err
+	n, err := io.ReadFull(conn, size)
+	if err != nil {
+		ELog.Printf("Read error: %s \n", err)
+		return nil, n, err
 	}
 
-	n, err = conn.Write([]byte(command))
-	if err != nil || n == 0 {
-		ELog.Printf("Write error: %s \n", err)
-		return nil, 0, err
+	buf := make([]byte, binary.BigEndian.Uint32(size))
+	m, err := io.ReadFull(conn, buf)
+	if err != nil {
+		ELog.Printf("Read error: %s \n", err)
+		return nil, n + m, err
 	}
+	return buf, n + m, nil
+}
 
-	n, err = conn.Read(size)
-	retSize := binary.BigEndian.Uint32(size)
+/*
+ * Sends the command with seq and returns its response. The responses of the
+ * earlier commands which timed out arrive late, and they are dropped by their
+ * seq. inSync is false if the error happened in the middle of a message, then
+ * the next message cannot be read from the connection.
+ */
+func sendCommand(conn net.Conn, command string, seq uint64) (respBuf []byte, inSync bool, err error) {
+	command, err = addSeq(command, seq)
+	if err != nil {
+		return nil, true, err
+	}
 
-	if err != nil || n == 0 {
-		ELog.Printf("Read error: %s \n", err)
-		return nil, 0, err
+	written, err := writeCommand(conn, command)
+	if err != nil {
+		return nil, written == 0, err
 	}
 
-	buf := make([]byte, 0)
-	tmp := make([]byte, retSize)
-	count := uint32(0)
 	for {
-		n, err = conn.Read(tmp)
+		buf, read, err := readResponse(conn)
 		if err != nil {
-			return nil, 0, err
+			return nil, read == 0, err
 		}
-		count += uint32(n)
-		buf = append(buf, tmp[:n]...)
-		if count >= retSize {
-			return buf, retSize, nil
+
+		var ucr UlaCommandResponse
+		if json.Unmarshal(buf, &ucr) == nil && ucr.Seq != 0 && ucr.Seq < seq {
+			WLog.Printf("Drop the late response of seq %d\n", ucr.Seq)
+			continue
 		}
+		return buf, true, nil
+	}
+}
+
+func newNodeResult(targetNodeAddr TargetNodeAddr, code NodeResultCode, result int, errText string) NodeResult {
+	return NodeResult{
+		NodeId:     targetNodeAddr.NodeId,
+		TargetAddr: targetNodeAddr.TargetAddr,
+		Code:       code,
+		Result:     result,
+		Error:      errText,
+	}
+}
+
+/* the command of the commit or abort phase has no layout, it refers to the prepared one by TxId */
+func genPhaseCommand(phase string, txId uint64) (string, error) {
+	acdata := ula.ApplyCommandData{
+		Phase: phase,
+		TxId:  txId,
+	}
+	jsonBytes, err := json.Marshal(acdata)
+	if err != nil {
+		return "", err
 	}
-	return buf, retSize, nil
+	return string(jsonBytes), nil
 }
 
-func handleConnectTarget(ums *UlaMultiConnector, chanId int, targetNodeAddr TargetNodeAddr, sendChan chan string, respChan chan UlaCommandResponse, wg *sync.WaitGroup) {
+func handleConnectTarget(ums *UlaMultiConnector, chanId int, targetNodeAddr TargetNodeAddr, sendChan chan nodeRequest, respChan chan NodeResult, wg *sync.WaitGroup) {
 
 	var err error
-	conn, err := connectTarget(targetNodeAddr.TargetAddr, 0)
+	policy := ums.policies[chanId]
+	conn, err := connectTarget(context.Background(), targetNodeAddr.TargetAddr, policy, 0)
 	if err != nil {
 		WLog.Println("Failed connect target: ", targetNodeAddr.TargetAddr, " err: ", err)
+		ums.setNodeUp(chanId, false, fmt.Sprintf("connect failed: %s", err))
 		wg.Done()
 		return
 	}
-	defer conn.Close()
+	defer func() {
+		conn.Close()
+	}()
 
-	Mutex.Lock()
-	ums.sendChans[chanId] = sendChan
-	ums.respChans[chanId] = respChan
-	Mutex.Unlock()
+	ums.setNodeChans(chanId, sendChan, respChan)
+	ums.setNodeConn(chanId, conn)
+	ums.setNodeUp(chanId, true, "connected")
 
 	wg.Done()
+	var seq uint64
 	for {
 		select {
-		case command := <-sendChan:
-			jsonCommand, err := ulavscreen.ApplyAndGenCommand(command, targetNodeAddr.NodeId)
-			if err != nil {
-				ELog.Printf("Apply and Generate command Fail: %s \n", err)
-				continue
+		case req, ok := <-sendChan:
+			/* the supervisor has closed the connection, and it connects the node again */
+			if !ok {
+				return
 			}
-			respBuf, respSize, err := sendCommand(conn, jsonCommand)
+			startTime := time.Now()
+			seq++
+			conn.SetDeadline(req.Deadline)
+			respBuf, inSync, err := sendCommand(conn, req.Command, seq)
+			conn.SetDeadline(time.Time{})
 			var ucr UlaCommandResponse
+			nr := newNodeResult(targetNodeAddr, NODE_RESULT_OK, 0, "")
 			if err != nil {
-				if err == io.EOF || errors.Is(err, syscall.EPIPE) {
+				timedOut := errors.Is(err, os.ErrDeadlineExceeded)
+				if timedOut && inSync {
+					/*
+					 * The connection is kept and the late response is dropped by its seq.
+					 * The supervisor decides if the node is down by the missed heartbeats.
+					 */
+					WLog.Println("Response timeout from ", targetNodeAddr.TargetAddr)
+					nr = newNodeResult(targetNodeAddr, NODE_RESULT_TIMEOUT, -1, "response timeout")
+					nr.TimedOut = true
+					nr.Latency = time.Since(startTime)
+					respChan <- nr
+					continue
+				}
+				/* the supervisor has closed the connection on heartbeat timeout and dropped sendChan */
+				if current, _ := ums.getNodeChans(chanId); current != sendChan {
+					return
+				}
+				/*
+				 * A deadline in the middle of a message leaves the stream out of sync, so the
+				 * connection is reset, but the node is marked down only if it cannot be reconnected.
+				 */
+				if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.EPIPE) || errors.Is(err, net.ErrClosed) || timedOut {
 					WLog.Println("Connection closed, Retrying connect to ", targetNodeAddr.TargetAddr)
 					conn.Close()
-					conn, err = connectTarget(targetNodeAddr.TargetAddr, 1)
+					nr = newNodeResult(targetNodeAddr, NODE_RESULT_UNREACHABLE, -1, fmt.Sprintf("connection closed: %s", err))
+					if timedOut {
+						nr = newNodeResult(targetNodeAddr, NODE_RESULT_TIMEOUT, -1, "response timeout")
+						nr.TimedOut = true
+					} else {
+						ums.setNodeUp(chanId, false, nr.Error)
+					}
+					nr.Latency = time.Since(startTime)
+					conn, err = connectTarget(context.Background(), targetNodeAddr.TargetAddr, policy, policy.RetryCount)
 					if err != nil {
 						WLog.Println("Reconnection failed for ", targetNodeAddr.TargetAddr)
-						Mutex.Lock()
-						ums.sendChans[chanId] = nil
-						ums.respChans[chanId] = nil
-						Mutex.Unlock()
+						ums.setNodeUp(chanId, false, fmt.Sprintf("reconnect failed: %s", err))
+						ums.setNodeChans(chanId, nil, nil)
+						ums.setNodeConn(chanId, nil)
+						respChan <- nr
 						return
 					}
 					ILog.Println("Successfully reconnected to ", targetNodeAddr.TargetAddr)
+					ums.setNodeConn(chanId, conn)
+					ums.setNodeUp(chanId, true, "reconnected")
+					respChan <- nr
 					continue
 				}
 				ELog.Printf("Send command Fail: %s \n", err)
+				nr.Code = NODE_RESULT_UNREACHABLE
+				nr.Result = -1
+				nr.Error = fmt.Sprintf("send command error: %s", err)
 			} else {
-				err = json.Unmarshal([]byte(string(respBuf[:respSize])), &ucr)
+				err = json.Unmarshal(respBuf, &ucr)
 				if err != nil {
 					ELog.Printf("Unmarshal json command error: %s \n", err)
+					nr.Code = NODE_RESULT_INTERNAL_ERROR
+					nr.Result = -1
+					nr.Error = fmt.Sprintf("invalid response: %s", err)
+				} else if req.Phase == ula.REQUEST_TYPE_STATUS || req.Phase == ula.REQUEST_TYPE_HEARTBEAT {
+					if ucr.Type != req.Phase || (req.Phase == ula.REQUEST_TYPE_STATUS && ucr.Status == nil) {
+						nr.Code = NODE_RESULT_INTERNAL_ERROR
+						nr.Result = -1
+						nr.Error = req.Phase + " format type miss matched"
+					} else {
+						nr.Result = ucr.Result
+						nr.Status = ucr.Status
+					}
+				} else if ucr.Type != "result" {
+					nr.Code = NODE_RESULT_INTERNAL_ERROR
+					nr.Result = -1
+					nr.Error = "result format type miss matched"
+				} else {
+					nr.Result = ucr.Result
+					if ucr.Result != 0 && req.Phase == ula.PHASE_PREPARE {
+						nr.Code = NODE_RESULT_VALIDATION_ERROR
+						nr.Error = fmt.Sprintf("ula-node rejected the command: %d", ucr.Result)
+					} else if ucr.Result != 0 {
+						nr.Code = NODE_RESULT_COMPOSITOR_FAILURE
+						nr.Error = fmt.Sprintf("ula-node returned %d", ucr.Result)
+					}
 				}
 			}
+			nr.Latency = time.Since(startTime)
 
-			respChan <- ucr
+			respChan <- nr
 		}
 	}
 }
 
+func (ums *UlaMultiConnector) setNodeChans(chanId int, sendChan chan nodeRequest, respChan chan NodeResult) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	ums.sendChans[chanId] = sendChan
+	ums.respChans[chanId] = respChan
+}
+
+/* both are nil if the node is not connected */
+func (ums *UlaMultiConnector) getNodeChans(chanId int) (chan nodeRequest, chan NodeResult) {
+	nodeStateMutex.Lock()
+	defer nodeStateMutex.Unlock()
+
+	if ums.sendChans[chanId] == nil || ums.respChans[chanId] == nil {
+		return nil, nil
+	}
+	return ums.sendChans[chanId], ums.respChans[chanId]
+}
+
+func (ums *UlaMultiConnector) isConnected(chanId int) bool {
+	sendChan, _ := ums.getNodeChans(chanId)
+	return sendChan != nil
+}
+
 func (ums *UlaMultiConnector) countConnection() int {
 	connectNum := 0
 	for chanId := range ums.targetNodeAddrs {
-		if ums.sendChans[chanId] == nil {
-			continue
-		}
-		if ums.respChans[chanId] == nil {
-			continue
+		if ums.isConnected(chanId) {
+			connectNum++
 		}
-		connectNum++
 	}
 
 	return connectNum
 }
 
 func (ums *UlaMultiConnector) handleConnectTargets() {
+	chanIds := make([]int, 0)
+	for chanId := range ums.targetNodeAddrs {
+		chanIds = append(chanIds, chanId)
+	}
+	ums.connectTargets(chanIds)
+}
+
+/*
+ * The check and the setup of the connections are done under connectMutex,
+ * so the callers which find the same node not connected wait for the first
+ * one instead of connecting it again.
+ */
+func (ums *UlaMultiConnector) connectTargets(chanIds []int) {
+	connectMutex.Lock()
+	defer connectMutex.Unlock()
 
 	var wg sync.WaitGroup
-	for chanId, targetNodeAddr := range ums.targetNodeAddrs {
-		if ums.sendChans[chanId] == nil || ums.respChans[chanId] == nil {
+	for _, chanId := range chanIds {
+		targetNodeAddr := ums.targetNodeAddrs[chanId]
+		if !ums.isConnected(chanId) {
 			wg.Add(1)
-			sendChan := make(chan string, 1)
-			respChan := make(chan UlaCommandResponse, 1)
+			sendChan := make(chan nodeRequest, 1)
+			respChan := make(chan NodeResult, 1)
 			go handleConnectTarget(ums, chanId, targetNodeAddr, sendChan, respChan, &wg)
 		} else {
 			WLog.Println("targetNodeAddr ", targetNodeAddr, " has already connected to ula-node")
@@ -284,89 +494,311 @@ func (ums *UlaMultiConnector) handleConnectTargets() {
 	wg.Wait()
 }
 
-func waitResponse(waitTime time.Duration, respChan chan UlaCommandResponse, targetAddr string, wg *sync.WaitGroup, resp **UlaCommandResponse) {
+func waitResponse(ctx context.Context, deadline time.Time, respChan chan NodeResult, targetNodeAddr TargetNodeAddr, wg *sync.WaitGroup, resp *NodeResult) {
 
-	t := time.NewTicker(waitTime * time.Second)
-	defer t.Stop()
+	startTime := time.Now()
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
 	defer wg.Done()
 
 	select {
-	case ucr := <-respChan:
-		*resp = &ucr
+	case nr := <-respChan:
+		*resp = nr
 		break
-	case <-t.C:
-		timeoutResp := UlaCommandResponse{
-			Type:   "result",
-			Result: -1,
+	case <-ctx.Done():
+		*resp = newNodeResult(targetNodeAddr, NODE_RESULT_TIMEOUT, -1, "response timeout")
+		if ctx.Err() == context.Canceled {
+			resp.Error = "request canceled"
 		}
-		*resp = &timeoutResp
-		ELog.Printf("Command response watchdog was timeout. target: %s", targetAddr)
+		resp.TimedOut = true
+		resp.Latency = time.Since(startTime)
+		ELog.Printf("Command response watchdog was timeout. target: %s", targetNodeAddr.TargetAddr)
 		break
 	}
 }
 
-func (ums *UlaMultiConnector) sendCommand(command string) UlaCommandResponse {
-	Mutex.Lock()
+/* the deadline of the command to the node is the earlier one of its CommandTimeout and ctx */
+func (ums *UlaMultiConnector) commandDeadline(ctx context.Context, chanId int) time.Time {
+	deadline := time.Now().Add(ums.policies[chanId].CommandTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+/* drop a late response of the previous command which was timed out */
+func drainResponse(respChan chan NodeResult) {
+	for {
+		select {
+		case nr := <-respChan:
+			WLog.Printf("Drop the late response from %s: %d", nr.TargetAddr, nr.Result)
+		default:
+			return
+		}
+	}
+}
+
+/* sends the command of the phase to each node in commands and waits for their results */
+/*
+ * Sends the commands to the nodes and waits for their responses. If skipBusy
+ * is true, the nodes which are waiting for the response of another request
+ * are skipped and they are not in the results.
+ */
+func (ums *UlaMultiConnector) sendPhase(ctx context.Context, phase string, commands map[int]string, skipBusy bool) map[int]NodeResult {
 	var wg sync.WaitGroup
-	resps := make([]*UlaCommandResponse, len(ums.sendChans))
-	for chanId, sendChan := range ums.sendChans {
-		if sendChan != nil && ums.respChans[chanId] != nil {
-			wg.Add(1)
-			sendChan <- command
-			go waitResponse(1, ums.respChans[chanId], ums.targetNodeAddrs[chanId].TargetAddr, &wg, &resps[chanId])
+	resps := make([]NodeResult, len(ums.targetNodeAddrs))
+	sentIds := make([]int, 0)
+	for chanId, command := range commands {
+		sendMutex := &ums.supervisions[chanId].sendMutex
+		if !skipBusy {
+			sendMutex.Lock()
+		} else if !sendMutex.TryLock() {
+			continue
+		}
+		sentIds = append(sentIds, chanId)
+
+		sendChan, respChan := ums.getNodeChans(chanId)
+		if sendChan == nil {
+			sendMutex.Unlock()
+			resps[chanId] = newNodeResult(ums.targetNodeAddrs[chanId], NODE_RESULT_UNREACHABLE, -1, NOT_CONNECTED)
+			continue
 		}
+		wg.Add(1)
+		drainResponse(respChan)
+		deadline := ums.commandDeadline(ctx, chanId)
+		sendChan <- nodeRequest{Phase: phase, Command: command, Deadline: deadline}
+		go func(chanId int) {
+			defer sendMutex.Unlock()
+			waitResponse(ctx, deadline, respChan, ums.targetNodeAddrs[chanId], &wg, &resps[chanId])
+		}(chanId)
 	}
 	wg.Wait()
 
-	ret := mergeResponses(resps)
-	Mutex.Unlock()
+	results := make(map[int]NodeResult)
+	for _, chanId := range sentIds {
+		resps[chanId].Phase = phase
+		results[chanId] = resps[chanId]
+	}
+
+	return results
+}
 
-	return ret
+/* the same command of the commit or abort phase is sent to all nodes of chanIds */
+func genPhaseCommands(phase string, txId uint64, chanIds []int) (map[int]string, error) {
+	command, err := genPhaseCommand(phase, txId)
+	if err != nil {
+		return nil, err
+	}
+	commands := make(map[int]string)
+	for _, chanId := range chanIds {
+		commands[chanId] = command
+	}
+	return commands, nil
 }
 
-func (ums *UlaMultiConnector) SendLayoutCommand(command string) error {
+/*
+ * prepareVScreen prepares the new virtual screen only once, and the result is
+ * converted to the ApplyCommandData of each node.
+ * It is prepared on all connected nodes at first, and it is committed only if
+ * every node accepted it. If any node fails in prepare or commit, the command
+ * is aborted on the prepared nodes, and the committed ones roll back.
+ * VScreen is replaced only after all nodes committed.
+ * Prepare and commit wait until the deadline of ctx at the longest, but abort
+ * is always sent because the nodes must not be left prepared.
+ * If prepareVScreen fails, PrepareError is returned without any node result.
+ */
+func (ums *UlaMultiConnector) sendCommand(ctx context.Context, prepareVScreen func(txId uint64) error) ([]NodeResult, error) {
+	Mutex.Lock()
+	defer Mutex.Unlock()
+
+	ums.txId++
+	txId := ums.txId
+
+	chanIds := make([]int, 0)
+	for chanId := range ums.targetNodeAddrs {
+		chanIds = append(chanIds, chanId)
+	}
+
+	results := make(map[int]NodeResult)
+	err := prepareVScreen(txId)
+	if err != nil {
+		return nil, &PrepareError{Err: err}
+	}
+
+	commands := make(map[int]string)
+	for _, chanId := range chanIds {
+		jsonCommand, err := ulavscreen.GenPrepareCommand(txId, ums.targetNodeAddrs[chanId].NodeId)
+		if err != nil {
+			ELog.Printf("Generate command Fail: %s \n", err)
+			nr := newNodeResult(ums.targetNodeAddrs[chanId], NODE_RESULT_VALIDATION_ERROR, -1, fmt.Sprintf("generate command error: %s", err))
+			nr.Phase = ula.PHASE_PREPARE
+			results[chanId] = nr
+			continue
+		}
+		commands[chanId] = jsonCommand
+	}
+
+	for chanId, nr := range ums.sendPhase(ctx, ula.PHASE_PREPARE, commands, false) {
+		results[chanId] = nr
+	}
+
+	preparedIds := make([]int, 0)
+	for _, chanId := range chanIds {
+		if results[chanId].Result == 0 {
+			preparedIds = append(preparedIds, chanId)
+		}
+	}
+
+	isSucceeded := checkNodeResults(mapToNodeResults(results, chanIds), ums.force) == nil && len(preparedIds) != 0
+	if isSucceeded && ctx.Err() != nil {
+		WLog.Printf("TxId %d is not committed: %s", txId, ctx.Err())
+		isSucceeded = false
+	}
+	if isSucceeded {
+		commitCommands, err := genPhaseCommands(ula.PHASE_COMMIT, txId, preparedIds)
+		if err != nil {
+			ELog.Println(err)
+			isSucceeded = false
+		} else {
+			commitResults := ums.sendPhase(ctx, ula.PHASE_COMMIT, commitCommands, false)
+			for _, chanId := range preparedIds {
+				nr := commitResults[chanId]
+				nr.Latency += results[chanId].Latency
+				results[chanId] = nr
+				if nr.Result != 0 {
+					isSucceeded = false
+				}
+			}
+		}
+	}
+
+	if isSucceeded {
+		err := ulavscreen.CommitVScreen(txId)
+		if err != nil {
+			ELog.Println(err)
+		}
+		return mapToNodeResults(results, chanIds), nil
+	}
+
+	WLog.Printf("Abort TxId %d", txId)
+	abortCommands, err := genPhaseCommands(ula.PHASE_ABORT, txId, preparedIds)
+	if err != nil {
+		ELog.Println(err)
+	} else {
+		abortResults := ums.sendPhase(context.Background(), ula.PHASE_ABORT, abortCommands, false)
+		for _, chanId := range preparedIds {
+			if abortResults[chanId].Result != 0 {
+				ELog.Printf("Abort failed on node %d: %s", abortResults[chanId].NodeId, abortResults[chanId].Error)
+			}
+		}
+	}
+	ulavscreen.AbortVScreen(txId)
+
+	return mapToNodeResults(results, chanIds), nil
+}
+
+func mapToNodeResults(results map[int]NodeResult, chanIds []int) []NodeResult {
+	nodeResults := make([]NodeResult, 0)
+	for _, chanId := range chanIds {
+		nodeResults = append(nodeResults, results[chanId])
+	}
+	return nodeResults
+}
+
+/*
+ * Sends the layout command to all ula-nodes and returns the result of each node.
+ * The error is not nil if the command failed on any node.
+ */
+func (ums *UlaMultiConnector) SendLayoutCommand(ctx context.Context, command string) ([]NodeResult, error) {
+	return ums.sendTransaction(ctx, func(txId uint64) error {
+		return ulavscreen.PrepareVScreen(command, txId)
+	})
+}
+
+/* re-applies the layout of the generation in the layout history to all ula-nodes */
+func (ums *UlaMultiConnector) SendRevertCommand(ctx context.Context, generation uint64) ([]NodeResult, error) {
+	return ums.sendTransaction(ctx, func(txId uint64) error {
+		return ulavscreen.PrepareVScreenFromHistory(generation, txId)
+	})
+}
+
+func (ums *UlaMultiConnector) sendTransaction(ctx context.Context, prepareVScreen func(txId uint64) error) ([]NodeResult, error) {
 	connectNum := ums.countConnection()
 	if connectNum < len(ums.targetNodeAddrs) {
 		ums.handleConnectTargets()
 		connectNum = ums.countConnection()
 		if connectNum == 0 {
-			return errors.New("All targets cannot connect master")
+			return ums.genUnconnectedNodeResults(), errors.New("All targets cannot connect master")
 		}
 
 		if !ums.force {
 			if connectNum < len(ums.targetNodeAddrs) {
-				return errors.New(fmt.Sprintf("Some targets cannot connect master (%d < %d)", connectNum, len(ums.targetNodeAddrs)))
+				return ums.genUnconnectedNodeResults(), errors.New(fmt.Sprintf("Some targets cannot connect master (%d < %d)", connectNum, len(ums.targetNodeAddrs)))
 			}
 		}
 	}
 
-	ucr := ums.sendCommand(command)
-	if ucr.Type == "result" {
-		ret := ucr.Result
-		if ret != 0 {
-			return errors.New("SendLayoutCommand Failed")
-		}
-	} else {
-		return errors.New("result format type miss matched")
+	nodeResults, err := ums.sendCommand(ctx, prepareVScreen)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return nodeResults, checkNodeResults(nodeResults, ums.force)
 }
 
-func mergeResponses(resps []*UlaCommandResponse) UlaCommandResponse {
-	var ret UlaCommandResponse
-	for _, resp := range resps {
-		if resp != nil {
-			ret.Type = resp.Type
-			switch ret.Type {
-			case "result":
-				ret.Result = ret.Result | resp.Result
-				break
-			}
+/*
+ * Queries the status of all ula-nodes. The nodes which cannot be connected
+ * are returned with NODE_RESULT_UNREACHABLE and nil Status.
+ */
+func (ums *UlaMultiConnector) GetNodeStatuses(ctx context.Context) []NodeResult {
+	if ums.countConnection() < len(ums.targetNodeAddrs) {
+		ums.handleConnectTargets()
+	}
+
+	Mutex.Lock()
+	defer Mutex.Unlock()
+
+	statusJson, _ := json.Marshal(map[string]string{"type": ula.REQUEST_TYPE_STATUS})
+
+	chanIds := make([]int, 0)
+	commands := make(map[int]string)
+	for chanId := range ums.targetNodeAddrs {
+		chanIds = append(chanIds, chanId)
+		commands[chanId] = string(statusJson)
+	}
+
+	return mapToNodeResults(ums.sendPhase(ctx, ula.REQUEST_TYPE_STATUS, commands, false), chanIds)
+}
+
+/* results of the nodes which are not connected, the command is not sent to any node */
+func (ums *UlaMultiConnector) genUnconnectedNodeResults() []NodeResult {
+	nodeResults := make([]NodeResult, 0)
+	for chanId, targetNodeAddr := range ums.targetNodeAddrs {
+		if !ums.isConnected(chanId) {
+			nodeResults = append(nodeResults, newNodeResult(targetNodeAddr, NODE_RESULT_UNREACHABLE, -1, NOT_CONNECTED))
 		}
 	}
-	return ret
+	return nodeResults
+}
 
+func checkNodeResults(nodeResults []NodeResult, force bool) error {
+	var failedIds []int
+	for _, nr := range nodeResults {
+		if nr.Result == 0 {
+			continue
+		}
+		/* the nodes which are not connected are allowed in force mode */
+		if force && nr.Error == NOT_CONNECTED {
+			continue
+		}
+		failedIds = append(failedIds, nr.NodeId)
+	}
+
+	if len(failedIds) != 0 {
+		return errors.New(fmt.Sprintf("SendLayoutCommand Failed on node %v", failedIds))
+	}
+
+	return nil
 }
 
 func getDistribNodes(vsdPath ...string) ([]DistribNode, error) {
@@ -386,6 +818,7 @@ func getDistribNodes(vsdPath ...string) ([]DistribNode, error) {
 			tmp.NodeId = node.NodeId
 			tmp.Ip = node.Ip
 			tmp.Port = frameworkNode.Ula.Port
+			tmp.Policy = vscrnDef.GetTimeoutPolicy(node.NodeId)
 			dNodes = append(dNodes, tmp)
 		}
 
@@ -401,5 +834,6 @@ func UlaConnectionInit(force bool, vsdPath ...string) error {
 	}
 
 	UlaMulCon.handleConnectTargets()
+	go UlaMulCon.superviseNodes()
 	return nil
 }
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulavscreen
+
+import (
+	"sync"
+	"ula-tools/internal/ula"
+	. "ula-tools/internal/ulog"
+)
+
+const LAYOUT_EVENT_CHAN_SIZE = 16
+
+/* notified each time a new VScreen is committed */
+type LayoutEvent struct {
+	Generation uint64
+	Command    string
+	ChgIds     []ula.IdPair
+}
+
+var (
+	layoutEventMutex   sync.Mutex
+	layoutGeneration   uint64
+	layoutSubscriberId int
+	layoutSubscribers  = make(map[int]chan LayoutEvent)
+)
+
+func SubscribeLayoutEvents() (int, chan LayoutEvent) {
+	layoutEventMutex.Lock()
+	defer layoutEventMutex.Unlock()
+
+	layoutSubscriberId++
+	eventChan := make(chan LayoutEvent, LAYOUT_EVENT_CHAN_SIZE)
+	layoutSubscribers[layoutSubscriberId] = eventChan
+
+	return layoutSubscriberId, eventChan
+}
+
+func UnsubscribeLayoutEvents(id int) {
+	layoutEventMutex.Lock()
+	defer layoutEventMutex.Unlock()
+
+	eventChan, ok := layoutSubscribers[id]
+	if !ok {
+		return
+	}
+	delete(layoutSubscribers, id)
+	close(eventChan)
+}
+
+func GetLayoutGeneration() uint64 {
+	layoutEventMutex.Lock()
+	defer layoutEventMutex.Unlock()
+
+	return layoutGeneration
+}
+
+/* advance the layout generation and notify all subscribers */
+func notifyLayoutEvent(acdata *ula.ApplyCommandData) uint64 {
+	layoutEventMutex.Lock()
+	defer layoutEventMutex.Unlock()
+
+	layoutGeneration++
+	for id, eventChan := range layoutSubscribers {
+		event := LayoutEvent{
+			Generation: layoutGeneration,
+			Command:    acdata.Command,
+			ChgIds:     append([]ula.IdPair{}, acdata.ChgIds...),
+		}
+		select {
+		case eventChan <- event:
+		default:
+			/*
+			 * the subscriber is too slow. Not to block the layout nor to lose an event silently,
+			 * its channel is closed after the queued events so that it reads the layout again.
+			 */
+			WLog.Printf("layout event subscriber %d is full at generation %d, unsubscribed", id, layoutGeneration)
+			delete(layoutSubscribers, id)
+			close(eventChan)
+		}
+	}
+
+	return layoutGeneration
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulavscreen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+	"ula-tools/internal/ula"
+)
+
+const LAYOUT_HISTORY_SIZE = 16
+
+const REVERT_COMMAND = "revert_layout"
+
+/* a committed VScreen and the generation it was committed as */
+type layoutSnapshot struct {
+	generation uint64
+	command    string
+	time       time.Time
+	vscrn      *VirtualScreen
+
+	/* the generation which undo returns to, 0 means nothing to undo */
+	undoGeneration uint64
+}
+
+type LayoutHistoryEntry struct {
+	Generation     uint64
+	Command        string
+	Time           time.Time
+	UndoGeneration uint64
+}
+
+var (
+	layoutHistoryMutex sync.Mutex
+	layoutHistory      = make([]layoutSnapshot, 0)
+)
+
+func findLayoutSnapshot(generation uint64) (*layoutSnapshot, error) {
+	for idx := range layoutHistory {
+		if layoutHistory[idx].generation == generation {
+			return &layoutHistory[idx], nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("generation %d is not in the layout history", generation))
+}
+
+/*
+ * record the committed VScreen. Undo of a revert returns to the layout before
+ * the reverted one, so that undo can be repeated.
+ */
+func pushLayoutHistory(generation uint64, pending *pendingVScreen) {
+	layoutHistoryMutex.Lock()
+	defer layoutHistoryMutex.Unlock()
+
+	var undoGeneration uint64
+	if pending.revertGeneration != 0 {
+		if snapshot, err := findLayoutSnapshot(pending.revertGeneration); err == nil {
+			undoGeneration = snapshot.undoGeneration
+		}
+	} else if _, err := findLayoutSnapshot(generation - 1); err == nil {
+		undoGeneration = generation - 1
+	}
+
+	layoutHistory = append(layoutHistory, layoutSnapshot{
+		generation:     generation,
+		command:        pending.acdata.Command,
+		time:           time.Now(),
+		vscrn:          pending.vscrn.Dup(),
+		undoGeneration: undoGeneration,
+	})
+	if len(layoutHistory) > LAYOUT_HISTORY_SIZE {
+		layoutHistory = append([]layoutSnapshot{}, layoutHistory[len(layoutHistory)-LAYOUT_HISTORY_SIZE:]...)
+	}
+}
+
+/* returns the layout history, the oldest first */
+func GetLayoutHistory() []LayoutHistoryEntry {
+	layoutHistoryMutex.Lock()
+	defer layoutHistoryMutex.Unlock()
+
+	entries := make([]LayoutHistoryEntry, 0)
+	for _, snapshot := range layoutHistory {
+		entries = append(entries, LayoutHistoryEntry{
+			Generation:     snapshot.generation,
+			Command:        snapshot.command,
+			Time:           snapshot.time,
+			UndoGeneration: snapshot.undoGeneration,
+		})
+	}
+	return entries
+}
+
+/* returns the generation which undo of the current layout returns to */
+func GetUndoGeneration() (uint64, error) {
+	generation := GetLayoutGeneration()
+
+	layoutHistoryMutex.Lock()
+	defer layoutHistoryMutex.Unlock()
+
+	snapshot, err := findLayoutSnapshot(generation)
+	if err != nil {
+		return 0, err
+	}
+	if snapshot.undoGeneration == 0 {
+		return 0, errors.New(fmt.Sprintf("generation %d has no layout to undo", generation))
+	}
+	if _, err := findLayoutSnapshot(snapshot.undoGeneration); err != nil {
+		return 0, err
+	}
+	return snapshot.undoGeneration, nil
+}
+
+/*
+ * Prepares the VScreen of the generation in the history as a new layout.
+ * It is committed or aborted by CommitVScreen/AbortVScreen like PrepareVScreen.
+ */
+func PrepareVScreenFromHistory(generation uint64, txId uint64) error {
+	layoutHistoryMutex.Lock()
+	snapshot, err := findLayoutSnapshot(generation)
+	var vscrn *VirtualScreen
+	if err == nil {
+		vscrn = snapshot.vscrn.Dup()
+	}
+	layoutHistoryMutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	chgIds := genRevertChgIds(GetVScreen(), vscrn)
+
+	pendingMutex.Lock()
+	pendingVScreens[txId] = &pendingVScreen{
+		vscrn:            vscrn,
+		acdata:           &ula.ApplyCommandData{Command: REVERT_COMMAND, ChgIds: chgIds},
+		revertGeneration: generation,
+	}
+	pendingMutex.Unlock()
+
+	return nil
+}
+
+/* the position of a virtual layer in VdispVlayers */
+type vlayerPos struct {
+	vdspid int
+	idx    int
+	vlayer *ula.VirtualLayer
+}
+
+func genVlayerPosMap(vscrn *VirtualScreen) map[int]vlayerPos {
+	posMap := make(map[int]vlayerPos)
+	if vscrn == nil {
+		return posMap
+	}
+	for vdspid, vlayers := range vscrn.VdispVlayers {
+		for idx := range vlayers {
+			posMap[vlayers[idx].VID] = vlayerPos{vdspid: vdspid, idx: idx, vlayer: &vlayers[idx]}
+		}
+	}
+	return posMap
+}
+
+/*
+ * Returns the IDs which differ between the current VScreen and the VScreen to revert to,
+ * in the same form as the layout commands.
+ * A layer which is added, removed, restacked or changed itself is reported with SurfaceId -1,
+ * and a surface which is added, removed or changed is reported with its layer.
+ */
+func genRevertChgIds(cur *VirtualScreen, target *VirtualScreen) []ula.IdPair {
+	curPos := genVlayerPosMap(cur)
+	targetPos := genVlayerPosMap(target)
+
+	chgIdMap := make(map[ula.IdPair]bool)
+	for layerId := range curPos {
+		if _, ok := targetPos[layerId]; !ok {
+			chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: -1}] = true
+		}
+	}
+
+	for layerId, tpos := range targetPos {
+		cpos, ok := curPos[layerId]
+		if !ok {
+			for _, idPair := range genLayerIdPairs(tpos.vlayer) {
+				chgIdMap[idPair] = true
+			}
+			continue
+		}
+
+		curLayer := *cpos.vlayer
+		targetLayer := *tpos.vlayer
+		curLayer.Vsurfaces = nil
+		targetLayer.Vsurfaces = nil
+		if cpos.vdspid != tpos.vdspid || cpos.idx != tpos.idx || !reflect.DeepEqual(curLayer, targetLayer) {
+			chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: -1}] = true
+		}
+
+		curSurfaces := make(map[int]int)
+		for i, vsurface := range cpos.vlayer.Vsurfaces {
+			curSurfaces[vsurface.VID] = i
+		}
+		for i, vsurface := range tpos.vlayer.Vsurfaces {
+			ci, ok := curSurfaces[vsurface.VID]
+			if !ok || !reflect.DeepEqual(cpos.vlayer.Vsurfaces[ci], vsurface) {
+				chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: vsurface.VID}] = true
+			} else if ci != i {
+				chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: -1}] = true
+			}
+			delete(curSurfaces, vsurface.VID)
+		}
+		for surfaceId := range curSurfaces {
+			chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: surfaceId}] = true
+		}
+	}
+
+	chgIds := make([]ula.IdPair, 0)
+	for idPair := range chgIdMap {
+		chgIds = append(chgIds, idPair)
+	}
+	sort.Slice(chgIds, func(i, j int) bool {
+		if chgIds[i].LayerId != chgIds[j].LayerId {
+			return chgIds[i].LayerId < chgIds[j].LayerId
+		}
+		return chgIds[i].SurfaceId < chgIds[j].SurfaceId
+	})
+
+	return chgIds
+}
@@ -20,7 +20,9 @@ package ulavscreen
 import (
 	"encoding/json"
 	"errors"
-	_ "fmt"
+	"fmt"
+	"reflect"
+	"sort"
 	"sync"
 	"ula-tools/internal/ula"
 	. "ula-tools/internal/ulog"
@@ -63,6 +65,34 @@ func getIntFromJsonDef(mJson map[string]interface{}, key string, defval int) (in
 	return val, nil
 }
 
+func getFloatFromJsonDef(mJson map[string]interface{}, key string, defval float64) (float64, error) {
+
+	tval := mJson[key]
+	if tval == nil {
+		return defval, nil
+	}
+	val, ok := tval.(float64)
+	if !ok {
+		return 0, errors.New("Error in getFloatFromJsonDef")
+	}
+
+	return val, nil
+}
+
+func getOpacityFromJsonDef(mJson map[string]interface{}, defval float64) (float64, error) {
+
+	opacity, err := getFloatFromJsonDef(mJson, "opacity", defval)
+	if err != nil {
+		return 0, err
+	}
+
+	if opacity < 0.0 || opacity > 1.0 {
+		return 0, errors.New("opacity should be in the range of 0.0 to 1.0")
+	}
+
+	return opacity, nil
+}
+
 func getSliceFromJson(mJson map[string]interface{}, key string) ([]interface{}, error) {
 
 	tval := mJson[key]
@@ -166,6 +196,11 @@ func generateSurfaceFromParam(layerId int, mSurface map[string]interface{}, appl
 		return nil, err
 	}
 
+	opacity, err := getOpacityFromJsonDef(mSurface, 1.0)
+	if err != nil {
+		return nil, err
+	}
+
 	vsurface := ula.VirtualSurface{
 		AppName:    appli_name,
 		ParentVID:  layerId,
@@ -181,6 +216,7 @@ func generateSurfaceFromParam(layerId int, mSurface map[string]interface{}, appl
 		VdstW:      vdstW,
 		VdstH:      vdstH,
 		Visibility: visibility,
+		Opacity:    opacity,
 	}
 
 	return &vsurface, nil
@@ -193,8 +229,10 @@ type VirtualScreen struct {
 	VirtualWidth  int
 	VirtualHeight int
 
-	VirtualDisplays   map[int]ula.VirtualDisplay
-	RealDisplays      map[int]ula.RealDisplay
+	VirtualDisplays map[int]ula.VirtualDisplay
+
+	/* the real displays which show each virtual display, keyed by VDisplayId */
+	RealDisplays      map[int][]ula.RealDisplay
 	VdispVlayers      map[int][]ula.VirtualLayer
 	VdispVsafetyAreas map[int][]ula.VirtualSafetyArea
 }
@@ -206,7 +244,7 @@ func NewVirtualScreen(vscrnDef *ula.VScrnDef) (*VirtualScreen, error) {
 		VirtualWidth:      vscrnDef.Def2D.Size.VirtualW,
 		VirtualHeight:     vscrnDef.Def2D.Size.VirtualH,
 		VirtualDisplays:   make(map[int]ula.VirtualDisplay),
-		RealDisplays:      make(map[int]ula.RealDisplay),
+		RealDisplays:      make(map[int][]ula.RealDisplay),
 		VdispVlayers:      make(map[int][]ula.VirtualLayer),
 		VdispVsafetyAreas: make(map[int][]ula.VirtualSafetyArea),
 	}
@@ -224,13 +262,15 @@ func NewVirtualScreen(vscrnDef *ula.VScrnDef) (*VirtualScreen, error) {
 	}
 
 	for _, r := range vscrnDef.RealDisplays {
-		vscreen.RealDisplays[r.VDisplayId] = ula.RealDisplay{
+		rdisplay := ula.RealDisplay{
 			NodeId:     r.NodeId,
 			VDisplayId: r.VDisplayId,
 			PixelW:     r.PixelW,
 			PixelH:     r.PixelH,
 			RDisplayId: r.RDisplayId,
+			Transform:  ula.NormalizeTransform(r.Transform),
 		}
+		vscreen.RealDisplays[r.VDisplayId] = append(vscreen.RealDisplays[r.VDisplayId], rdisplay)
 	}
 
 	vVdispVsafetyAreas := make([]ula.VirtualSafetyArea, 0)
@@ -254,13 +294,13 @@ func (vscreen *VirtualScreen) Dup() *VirtualScreen {
 	vScreenMutex.RLock()
 	defer vScreenMutex.RUnlock()
 	copiedVDsps := make(map[int]ula.VirtualDisplay)
-	copiedRDsps := make(map[int]ula.RealDisplay)
+	copiedRDsps := make(map[int][]ula.RealDisplay)
 	copiedVDispVLayers := make(map[int][]ula.VirtualLayer)
 	copiedVdispVsafetyAreas := make(map[int][]ula.VirtualSafetyArea)
 
 	for vdspid, vdisplay := range vscreen.VirtualDisplays {
 		copiedVDsp := vdisplay
-		copiedRDsp := vscreen.RealDisplays[vdspid]
+		copiedRDsp := append([]ula.RealDisplay{}, vscreen.RealDisplays[vdspid]...)
 		copiedVLayers := vscreen.VdispVlayers[vdspid]
 		copiedVSafetyAreas := vscreen.VdispVsafetyAreas[vdspid]
 
@@ -291,6 +331,27 @@ func (vscrn *VirtualScreen) ApplyCommand(mJson map[string]interface{}) (*ula.App
 	case "initial_vscreen":
 		DLog.Println("@@INITIAL_VSCREEN@@")
 		chgIds, err = initVirtualScreen(vscrn, mJson)
+	case "set_vlayer":
+		DLog.Println("@@SET_VLAYER@@")
+		chgIds, err = setVirtualLayer(vscrn, mJson)
+	case "add_vlayer":
+		DLog.Println("@@ADD_VLAYER@@")
+		chgIds, err = addVirtualLayer(vscrn, mJson)
+	case "remove_vlayer":
+		DLog.Println("@@REMOVE_VLAYER@@")
+		chgIds, err = removeVirtualLayer(vscrn, mJson)
+	case "raise_vlayer":
+		DLog.Println("@@RAISE_VLAYER@@")
+		chgIds, err = restackVirtualLayer(vscrn, mJson, command)
+	case "lower_vlayer":
+		DLog.Println("@@LOWER_VLAYER@@")
+		chgIds, err = restackVirtualLayer(vscrn, mJson, command)
+	case "restack_vlayer":
+		DLog.Println("@@RESTACK_VLAYER@@")
+		chgIds, err = restackVirtualLayer(vscrn, mJson, command)
+	case "set_opacity":
+		DLog.Println("@@SET_OPACITY@@")
+		chgIds, err = setOpacity(vscrn, mJson)
 	default:
 		chgIds = make([]ula.IdPair, 0)
 	}
@@ -323,57 +384,527 @@ func initVirtualScreen(vscreen *VirtualScreen, mJson map[string]interface{}) ([]
 	return make([]ula.IdPair, 0), nil
 }
 
-func ApplyAndGenCommand(command string, nodeId int) (string, error) {
+func findVlayerIndex(vlayers []ula.VirtualLayer, layerId int) int {
+	for idx, vlayer := range vlayers {
+		if vlayer.VID == layerId {
+			return idx
+		}
+	}
+	return -1
+}
+
+/* merge the given params into the existing virtual layer which has the same VID */
+func setVirtualLayer(vscreen *VirtualScreen, mJson map[string]interface{}) ([]ula.IdPair, error) {
+
+	layerId, err := getIntFromJson(mJson, "VID")
+	if err != nil {
+		ELog.Println("err in setVirtualLayer")
+		return make([]ula.IdPair, 0), err
+	}
+
+	found := false
+	changed := false
+	for vdspid, vlayers := range vscreen.VdispVlayers {
+		idx := findVlayerIndex(vlayers, layerId)
+		if idx < 0 {
+			continue
+		}
+		found = true
+
+		newVlayer, err := generateLayerFromParam(mJson, false, &vlayers[idx])
+		if err != nil {
+			ELog.Println("err in setVirtualLayer")
+			return make([]ula.IdPair, 0), err
+		}
+		if reflect.DeepEqual(*newVlayer, vlayers[idx]) {
+			continue
+		}
+
+		newVlayers := ula.DupVirtualLayerSlice(vlayers)
+		newVlayers[idx] = *newVlayer
+		vscreen.VdispVlayers[vdspid] = newVlayers
+		changed = true
+	}
+
+	if !found {
+		return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("set_vlayer: VID %d does not exist", layerId))
+	}
+
+	chgIds := make([]ula.IdPair, 0)
+	if changed {
+		chgIds = append(chgIds, ula.IdPair{LayerId: layerId, SurfaceId: -1})
+	}
+
+	return chgIds, nil
+}
+
+func genLayerIdPairs(vlayer *ula.VirtualLayer) []ula.IdPair {
+	idPairs := make([]ula.IdPair, 0)
+	idPairs = append(idPairs, ula.IdPair{LayerId: vlayer.VID, SurfaceId: -1})
+	for _, vsurface := range vlayer.Vsurfaces {
+		idPairs = append(idPairs, ula.IdPair{LayerId: vlayer.VID, SurfaceId: vsurface.VID})
+	}
+	return idPairs
+}
+
+/* insert a new virtual layer on top of the existing virtual layers */
+func addVirtualLayer(vscreen *VirtualScreen, mJson map[string]interface{}) ([]ula.IdPair, error) {
+
+	newVlayer, err := generateLayerFromParam(mJson, true, nil)
+	if err != nil {
+		ELog.Println("err in addVirtualLayer")
+		return make([]ula.IdPair, 0), err
+	}
+
+	for _, vlayers := range vscreen.VdispVlayers {
+		if findVlayerIndex(vlayers, newVlayer.VID) >= 0 {
+			return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("add_vlayer: VID %d already exists", newVlayer.VID))
+		}
+	}
+
+	for vdspid, vlayers := range vscreen.VdispVlayers {
+		newVlayers := ula.DupVirtualLayerSlice(vlayers)
+		newVlayers = append(newVlayers, *newVlayer.Dup())
+		vscreen.VdispVlayers[vdspid] = newVlayers
+	}
+
+	return genLayerIdPairs(newVlayer), nil
+}
+
+/* delete the virtual layer which has the given VID together with its virtual surfaces */
+func removeVirtualLayer(vscreen *VirtualScreen, mJson map[string]interface{}) ([]ula.IdPair, error) {
+
+	layerId, err := getIntFromJson(mJson, "VID")
+	if err != nil {
+		ELog.Println("err in removeVirtualLayer")
+		return make([]ula.IdPair, 0), err
+	}
+
+	var removedVlayer *ula.VirtualLayer
+	for vdspid, vlayers := range vscreen.VdispVlayers {
+		idx := findVlayerIndex(vlayers, layerId)
+		if idx < 0 {
+			continue
+		}
+		removedVlayer = vlayers[idx].Dup()
+
+		newVlayers := ula.DupVirtualLayerSlice(vlayers[:idx])
+		newVlayers = append(newVlayers, ula.DupVirtualLayerSlice(vlayers[idx+1:])...)
+		vscreen.VdispVlayers[vdspid] = newVlayers
+	}
+
+	if removedVlayer == nil {
+		return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("remove_vlayer: VID %d does not exist", layerId))
+	}
+
+	return genLayerIdPairs(removedVlayer), nil
+}
+
+/*
+ * move the virtual layer in the layer stack. The last layer in the slice is the top.
+ *   raise_vlayer:   {"VID": id}                      move to the top
+ *   lower_vlayer:   {"VID": id}                      move to the bottom
+ *   restack_vlayer: {"VID": id, "above"|"below": id} move directly above/below the reference layer
+ */
+func restackVirtualLayer(vscreen *VirtualScreen, mJson map[string]interface{}, command string) ([]ula.IdPair, error) {
+
+	layerId, err := getIntFromJson(mJson, "VID")
+	if err != nil {
+		ELog.Println("err in restackVirtualLayer")
+		return make([]ula.IdPair, 0), err
+	}
+
+	var (
+		insertOrder string
+		referenceId int
+	)
+	if command == "restack_vlayer" {
+		aboveId, errAbove := getIntFromJson(mJson, "above")
+		belowId, errBelow := getIntFromJson(mJson, "below")
+		if errAbove == nil && errBelow == nil {
+			return make([]ula.IdPair, 0), errors.New("restack_vlayer: only one of above and below can be specified")
+		} else if errAbove == nil {
+			insertOrder = "above"
+			referenceId = aboveId
+		} else if errBelow == nil {
+			insertOrder = "below"
+			referenceId = belowId
+		} else {
+			return make([]ula.IdPair, 0), errors.New("restack_vlayer: above or below is required")
+		}
+		if referenceId == layerId {
+			return make([]ula.IdPair, 0), errors.New("restack_vlayer: VID and reference VID should be different")
+		}
+	}
+
+	found := false
+	changed := false
+	for vdspid, vlayers := range vscreen.VdispVlayers {
+		idx := findVlayerIndex(vlayers, layerId)
+		if idx < 0 {
+			continue
+		}
+		found = true
+
+		target := vlayers[idx].Dup()
+		newVlayers := ula.DupVirtualLayerSlice(vlayers[:idx])
+		newVlayers = append(newVlayers, ula.DupVirtualLayerSlice(vlayers[idx+1:])...)
+
+		var pos int
+		switch command {
+		case "raise_vlayer":
+			pos = len(newVlayers)
+		case "lower_vlayer":
+			pos = 0
+		default:
+			pos = findVlayerIndex(newVlayers, referenceId)
+			if pos < 0 {
+				return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("restack_vlayer: reference VID %d does not exist", referenceId))
+			}
+			if insertOrder == "above" {
+				pos++
+			}
+		}
+
+		newVlayers = append(newVlayers[:pos], append([]ula.VirtualLayer{*target}, newVlayers[pos:]...)...)
+		if pos != idx {
+			changed = true
+		}
+		vscreen.VdispVlayers[vdspid] = newVlayers
+	}
+
+	if !found {
+		return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("%s: VID %d does not exist", command, layerId))
+	}
+
+	chgIds := make([]ula.IdPair, 0)
+	if changed {
+		chgIds = append(chgIds, ula.IdPair{LayerId: layerId, SurfaceId: -1})
+	}
+
+	return chgIds, nil
+}
+
+/*
+ * change the opacity of the virtual layer and/or its virtual surfaces.
+ *   {"VID": id, "opacity": 0.5, "vsurface": [{"VID": id, "opacity": 0.8}]}
+ * "opacity" and "vsurface" are both optional, but at least one is needed.
+ */
+func setOpacity(vscreen *VirtualScreen, mJson map[string]interface{}) ([]ula.IdPair, error) {
+
+	layerId, err := getIntFromJson(mJson, "VID")
+	if err != nil {
+		ELog.Println("err in setOpacity")
+		return make([]ula.IdPair, 0), err
+	}
+
+	var layerOpacity *float64
+	if mJson["opacity"] != nil {
+		opacity, err := getOpacityFromJsonDef(mJson, 1.0)
+		if err != nil {
+			return make([]ula.IdPair, 0), err
+		}
+		layerOpacity = &opacity
+	}
+
+	surfaceOpacities := make(map[int]float64)
+	if mJson["vsurface"] != nil {
+		surfaces, err := getSliceFromJson(mJson, "vsurface")
+		if err != nil {
+			return make([]ula.IdPair, 0), err
+		}
+		for _, surface := range surfaces {
+			mSurface, ok := surface.(map[string]interface{})
+			if !ok {
+				return make([]ula.IdPair, 0), errors.New("set_opacity: invalid vsurface")
+			}
+			surfaceId, err := getIntFromJson(mSurface, "VID")
+			if err != nil {
+				return make([]ula.IdPair, 0), err
+			}
+			if mSurface["opacity"] == nil {
+				return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("set_opacity: opacity of surface VID %d is not specified", surfaceId))
+			}
+			opacity, err := getOpacityFromJsonDef(mSurface, 1.0)
+			if err != nil {
+				return make([]ula.IdPair, 0), err
+			}
+			surfaceOpacities[surfaceId] = opacity
+		}
+	}
+
+	if layerOpacity == nil && len(surfaceOpacities) == 0 {
+		return make([]ula.IdPair, 0), errors.New("set_opacity: opacity or vsurface is needed")
+	}
+
+	found := false
+	chgIdMap := make(map[ula.IdPair]bool)
+	for vdspid, vlayers := range vscreen.VdispVlayers {
+		idx := findVlayerIndex(vlayers, layerId)
+		if idx < 0 {
+			continue
+		}
+		found = true
+
+		newVlayers := ula.DupVirtualLayerSlice(vlayers)
+		newVlayer := &newVlayers[idx]
+		if layerOpacity != nil && newVlayer.Opacity != *layerOpacity {
+			newVlayer.Opacity = *layerOpacity
+			chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: -1}] = true
+		}
+
+		for surfaceId, opacity := range surfaceOpacities {
+			sidx := -1
+			for i, vsurface := range newVlayer.Vsurfaces {
+				if vsurface.VID == surfaceId {
+					sidx = i
+					break
+				}
+			}
+			if sidx < 0 {
+				return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("set_opacity: surface VID %d does not exist in VID %d", surfaceId, layerId))
+			}
+			if newVlayer.Vsurfaces[sidx].Opacity != opacity {
+				newVlayer.Vsurfaces[sidx].Opacity = opacity
+				chgIdMap[ula.IdPair{LayerId: layerId, SurfaceId: surfaceId}] = true
+			}
+		}
+
+		vscreen.VdispVlayers[vdspid] = newVlayers
+	}
+
+	if !found {
+		return make([]ula.IdPair, 0), errors.New(fmt.Sprintf("set_opacity: VID %d does not exist", layerId))
+	}
+
+	chgIds := make([]ula.IdPair, 0)
+	for idPair := range chgIdMap {
+		chgIds = append(chgIds, idPair)
+	}
+	sort.Slice(chgIds, func(i, j int) bool {
+		return chgIds[i].SurfaceId < chgIds[j].SurfaceId
+	})
+
+	return chgIds, nil
+}
+
+/* convert the virtual screen into the pixel screens of the node */
+func GenNodePixelScreens(vscrn *VirtualScreen, nodeId int) (*ula.NodePixelScreens, error) {
+	vs2rdConv, err := NewVscreen2RdisplayConverter(vscrn, nodeId)
+	if err != nil {
+		ELog.Printf("Failed to create converter: %s\n", err)
+		return nil, err
+	}
+
+	var vsconv GeometoryConverter = vs2rdConv
+	vsconv.DoConvert()
+
+	npscreens, err := vsconv.GetNodePixelScreens()
+	if err != nil {
+		ELog.Printf("GetNodePixelScreens error: %s\n", err)
+		return nil, err
+	}
+
+	return npscreens, nil
+}
+
+/* returns a copy of the current virtual screen */
+func GetVScreen() *VirtualScreen {
+	vScreenMutex.RLock()
+	vscrn := VScreen
+	vScreenMutex.RUnlock()
+	if vscrn == nil {
+		return nil
+	}
+	return vscrn.Dup()
+}
+
+/* returns the node ids which have real displays, in ascending order */
+func (vscrn *VirtualScreen) GetNodeIds() []int {
+	nodeIdMap := make(map[int]bool)
+	nodeIds := make([]int, 0)
+	for _, rdisplays := range vscrn.RealDisplays {
+		for _, rdisplay := range rdisplays {
+			if nodeIdMap[rdisplay.NodeId] {
+				continue
+			}
+			nodeIdMap[rdisplay.NodeId] = true
+			nodeIds = append(nodeIds, rdisplay.NodeId)
+		}
+	}
+	sort.Ints(nodeIds)
+	return nodeIds
+}
+
+type pendingVScreen struct {
+	vscrn  *VirtualScreen
+	acdata *ula.ApplyCommandData
+
+	/* the generation in the history which is re-applied, 0 for a layout command */
+	revertGeneration uint64
+}
+
+/* the virtual screens which are prepared but not committed yet, keyed by TxId */
+var pendingVScreens = make(map[uint64]*pendingVScreen)
+var pendingMutex sync.Mutex
+
+/*
+ * Applies the command to a copy of VScreen once for all nodes.
+ * VScreen is not replaced until CommitVScreen is called with the same txId.
+ */
+func PrepareVScreen(command string, txId uint64) error {
 	var applyCommand map[string]interface{}
 	if err := json.Unmarshal([]byte(command), &applyCommand); err != nil {
 		ELog.Printf("Unmarshal json command error: %s\n", err)
-		return "", err
+		return err
 	}
 
-	vscrnCopy := VScreen.Dup()
+	vscrnCopy := GetVScreen()
 
 	acdata, err := vscrnCopy.ApplyCommand(applyCommand)
 	if err != nil {
 		ELog.Printf("ApplyCommand error: %s\n", err)
-		return "", err
+		return err
 	}
 
-	vs2rdConv, err := NewVscreen2RdisplayConverter(vscrnCopy, nodeId)
+	pendingMutex.Lock()
+	pendingVScreens[txId] = &pendingVScreen{vscrn: vscrnCopy, acdata: acdata}
+	pendingMutex.Unlock()
+
+	return nil
+}
+
+/* the command which a node would receive, generated by DryRunVScreen */
+type DryRunNodeData struct {
+	NodeId int
+	Acdata *ula.ApplyCommandData
+
+	/* the pixel screens of the node before the command */
+	AppliedNPScreens *ula.NodePixelScreens
+}
+
+/*
+ * Applies the command to a copy of VScreen and generates the ApplyCommandData
+ * of each node in the same way as GenPrepareCommand. VScreen is not changed.
+ */
+func DryRunVScreen(command string) ([]DryRunNodeData, error) {
+	var applyCommand map[string]interface{}
+	if err := json.Unmarshal([]byte(command), &applyCommand); err != nil {
+		return nil, err
+	}
+	if _, ok := applyCommand["command"].(string); !ok {
+		return nil, errors.New("\"command\" is not specified")
+	}
+
+	vscrn := GetVScreen()
+	if vscrn == nil {
+		return nil, errors.New("DryRunVScreen: VScreen is not initialized")
+	}
+	vscrnCopy := vscrn.Dup()
+
+	acdata, err := vscrnCopy.ApplyCommand(applyCommand)
 	if err != nil {
-		ELog.Printf("Failed to create converter: %s\n", err)
-		return "", err
+		return nil, err
 	}
 
-	var vsconv GeometoryConverter = vs2rdConv
-	vsconv.DoConvert()
+	nodeDatas := make([]DryRunNodeData, 0)
+	for _, nodeId := range vscrnCopy.GetNodeIds() {
+		npscrns, err := GenNodePixelScreens(vscrnCopy, nodeId)
+		if err != nil {
+			return nil, err
+		}
+		appliedNPScrns, err := GenNodePixelScreens(vscrn, nodeId)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeDatas = append(nodeDatas, DryRunNodeData{
+			NodeId: nodeId,
+			Acdata: &ula.ApplyCommandData{
+				Command:    acdata.Command,
+				ChgIds:     acdata.ChgIds,
+				NPScreens:  npscrns,
+				Phase:      ula.PHASE_PREPARE,
+				Generation: GetLayoutGeneration() + 1,
+			},
+			AppliedNPScreens: appliedNPScrns,
+		})
+	}
 
-	acdata.NPScreens, err = vsconv.GetNodePixelScreens()
+	return nodeDatas, nil
+}
+
+/* converts the prepared virtual screen to the prepare command for the node */
+func GenPrepareCommand(txId uint64, nodeId int) (string, error) {
+	pendingMutex.Lock()
+	pending, ok := pendingVScreens[txId]
+	pendingMutex.Unlock()
+
+	if !ok {
+		return "", errors.New(fmt.Sprintf("GenPrepareCommand: TxId %d is not prepared", txId))
+	}
+
+	npscrns, err := GenNodePixelScreens(pending.vscrn, nodeId)
 	if err != nil {
-		ELog.Printf("GetNodePixelScreens error: %s\n", err)
 		return "", err
 	}
 
+	/* the transactions are committed one by one, so the next generation is the one of this command */
+	acdata := ula.ApplyCommandData{
+		Command:    pending.acdata.Command,
+		ChgIds:     pending.acdata.ChgIds,
+		NPScreens:  npscrns,
+		Phase:      ula.PHASE_PREPARE,
+		TxId:       txId,
+		Generation: GetLayoutGeneration() + 1,
+	}
+
 	jsonBytes, err := json.Marshal(acdata)
 	if err != nil {
 		ELog.Printf("Marshal ApplyCommandData error: %s\n", err)
 		return "", err
 	}
 
-	jsonCommand := string(jsonBytes)
+	return string(jsonBytes), nil
+}
+
+/* replace VScreen with the prepared one after the command is committed on all nodes */
+func CommitVScreen(txId uint64) error {
+	pendingMutex.Lock()
+	pending, ok := pendingVScreens[txId]
+	delete(pendingVScreens, txId)
+	pendingMutex.Unlock()
+
+	if !ok {
+		return errors.New(fmt.Sprintf("CommitVScreen: TxId %d is not prepared", txId))
+	}
 
 	vScreenMutex.Lock()
-	VScreen = vscrnCopy
+	VScreen = pending.vscrn
 	vScreenMutex.Unlock()
 
-	return jsonCommand, nil
+	generation := notifyLayoutEvent(pending.acdata)
+	pushLayoutHistory(generation, pending)
+
+	return nil
+}
+
+func AbortVScreen(txId uint64) {
+	pendingMutex.Lock()
+	delete(pendingVScreens, txId)
+	pendingMutex.Unlock()
 }
 
 func generateLayerFromParam(mLayer map[string]interface{}, genSurfaces bool, existingVlayer *ula.VirtualLayer) (*ula.VirtualLayer, error) {
 
 	appli_name, err := getStringFromJson(mLayer, "appli_name")
 	if err != nil {
-		ELog.Println("error in generateLayerFromParam")
-		return nil, err
+		if existingVlayer == nil {
+			ELog.Println("error in generateLayerFromParam")
+			return nil, err
+		} else {
+			appli_name = existingVlayer.AppName
+		}
 	}
 
 	layerId, err := getIntFromJson(mLayer, "VID")
@@ -536,8 +1067,22 @@ func generateLayerFromParam(mLayer map[string]interface{}, genSurfaces bool, exi
 		return nil, err
 	}
 
+	defOpacity := 1.0
+	if existingVlayer != nil {
+		defOpacity = existingVlayer.Opacity
+	}
+	opacity, err := getOpacityFromJsonDef(mLayer, defOpacity)
+	if err != nil {
+		ELog.Println("error in generateLayerFromParam")
+		return nil, err
+	}
+
 	vsurfaces := make([]ula.VirtualSurface, 0)
-	if genSurfaces {
+	if !genSurfaces && existingVlayer != nil {
+		for _, vsurface := range existingVlayer.Vsurfaces {
+			vsurfaces = append(vsurfaces, *vsurface.Dup())
+		}
+	} else if genSurfaces {
 		surfaces, err := getSliceFromJson(mLayer, "vsurface")
 		if err != nil {
 			ELog.Println("error in generateLayerFromParam")
@@ -570,6 +1115,7 @@ func generateLayerFromParam(mLayer map[string]interface{}, genSurfaces bool, exi
 		VdstW:      vdstW,
 		VdstH:      vdstH,
 		Visibility: visibility,
+		Opacity:    opacity,
 		Vsurfaces:  vsurfaces,
 	}
 
@@ -580,6 +1126,7 @@ func generateLayerFromParam(mLayer map[string]interface{}, genSurfaces bool, exi
 func fillVscreenFromParam(vscreen *VirtualScreen, mJson map[string]interface{}) error {
 
 	vlayers := make([]ula.VirtualLayer, 0)
+	zorders := make(map[int]int)
 
 	layers, err := getSliceFromJson(mJson, "vlayer")
 	if err != nil {
@@ -594,9 +1141,20 @@ func fillVscreenFromParam(vscreen *VirtualScreen, mJson map[string]interface{})
 			ELog.Println("err in fillVscreenFromParam")
 			return err
 		}
+		zorder, err := getIntFromJsonDef(mLayer.(map[string]interface{}), "z_order", 0)
+		if err != nil {
+			ELog.Println("err in fillVscreenFromParam")
+			return err
+		}
+		zorders[newVlayer.VID] = zorder
 		vlayers = append(vlayers, *newVlayer)
 	}
 
+	/* the layer which has the higher z_order is stacked on top */
+	sort.SliceStable(vlayers, func(i, j int) bool {
+		return zorders[vlayers[i].VID] < zorders[vlayers[j].VID]
+	})
+
 	for _, vdisp := range vscreen.VirtualDisplays {
 		vscreen.VdispVlayers[vdisp.VDisplayId] = vlayers
 	}
@@ -39,7 +39,9 @@ type WorkV2R struct {
 }
 
 type Vscreen2RdisplayConverter struct {
-	nodeId     int
+	nodeId int
+
+	/* keyed by RDisplayId, because a vdisplay can be mirrored to several rdisplays */
 	workV2RMap map[int]WorkV2R
 }
 
@@ -90,6 +92,7 @@ func convVSurface2PSurface(vsurf *ula.VirtualSurface) *ula.PixelSurface {
 	psurf.PdstH = vsurf.VdstH
 
 	psurf.Visibility = vsurf.Visibility
+	psurf.Opacity = vsurf.Opacity
 	return psurf
 }
 
@@ -115,6 +118,7 @@ func convVLayer2PLayer(vlayer *ula.VirtualLayer) *ula.PixelLayer {
 	player.PdstH = vlayer.VdstH
 
 	player.Visibility = vlayer.Visibility
+	player.Opacity = vlayer.Opacity
 
 	player.Psurfaces = make([]ula.PixelSurface, 0)
 
@@ -197,16 +201,20 @@ func generateWorkV2R(
 			continue
 		}
 
-		rdisplay := vscreen.RealDisplays[vdspid]
+		for _, rdisplay := range vscreen.RealDisplays[vdspid] {
+			if rdisplay.NodeId != nodeId {
+				continue
+			}
 
-		wvdisp := WorkV2R{
-			vdisplay:     *vdisplay.Dup(),
-			rdisplay:     *rdisplay.Dup(),
-			vlayers:      ula.DupVirtualLayerSliceIfNeed(vscreen.VdispVlayers[vdspid], isNeedForWorkV2R, vdspid),
-			vsafetyareas: ula.DupVirtualSafetyAreaSlice(vscreen.VdispVsafetyAreas[vdspid]),
-		}
+			wvdisp := WorkV2R{
+				vdisplay:     *vdisplay.Dup(),
+				rdisplay:     *rdisplay.Dup(),
+				vlayers:      ula.DupVirtualLayerSliceIfNeed(vscreen.VdispVlayers[vdspid], isNeedForWorkV2R, vdspid),
+				vsafetyareas: ula.DupVirtualSafetyAreaSlice(vscreen.VdispVsafetyAreas[vdspid]),
+			}
 
-		workV2RMap[vdspid] = wvdisp
+			workV2RMap[rdisplay.RDisplayId] = wvdisp
+		}
 	}
 
 	return workV2RMap, nil
@@ -425,8 +433,8 @@ func sAreaConvToRDisplayCoordinate(sVSafetyAreas []ula.VirtualSafetyArea,
 	vdisp_vw := vdisp.VirtualW
 	vdisp_vh := vdisp.VirtualH
 
-	rdisp_pixw := rdisp.PixelW
-	rdisp_pixh := rdisp.PixelH
+	/* size of the real display before the transform */
+	rdisp_pixw, rdisp_pixh := ula.TransformSize(rdisp.Transform, rdisp.PixelW, rdisp.PixelH)
 
 	for _, vSafetyArea := range sVSafetyAreas {
 
@@ -443,12 +451,44 @@ func sAreaConvToRDisplayCoordinate(sVSafetyAreas []ula.VirtualSafetyArea,
 		newVSafetyArea.VirtualY = vSafetyArea_vdy * rdisp_pixh / vdisp_vh
 		newVSafetyArea.VirtualH = vSafetyArea_vdh * rdisp_pixh / vdisp_vh
 
+		newVSafetyArea.VirtualX, newVSafetyArea.VirtualY, newVSafetyArea.VirtualW, newVSafetyArea.VirtualH =
+			ula.TransformRect(rdisp.Transform, rdisp_pixw, rdisp_pixh,
+				newVSafetyArea.VirtualX, newVSafetyArea.VirtualY, newVSafetyArea.VirtualW, newVSafetyArea.VirtualH)
+
 		dVSafetyAreas = append(dVSafetyAreas, *newVSafetyArea)
 	}
 
 	return dVSafetyAreas
 }
 
+/*
+ * Transforms the vlayer in the real display of the size rdisp_pixw x rdisp_pixh
+ * (before the transform). Only the destination geometry is transformed: the
+ * layer and the positions of its surfaces in the layer. The size and src of
+ * each surface stay in the coordinates of its buffer, because the compositor
+ * transforms the content of the buffer by "transform" itself.
+ */
+func transformVLayer(vlayer *ula.VirtualLayer, transform string, rdisp_pixw int, rdisp_pixh int) {
+	if ula.NormalizeTransform(transform) == ula.TRANSFORM_NORMAL {
+		return
+	}
+
+	vlayer.VdstX, vlayer.VdstY, vlayer.VdstW, vlayer.VdstH =
+		ula.TransformRect(transform, rdisp_pixw, rdisp_pixh, vlayer.VdstX, vlayer.VdstY, vlayer.VdstW, vlayer.VdstH)
+
+	vlayer.VsrcX, vlayer.VsrcY, vlayer.VsrcW, vlayer.VsrcH =
+		ula.TransformRect(transform, vlayer.VirtualW, vlayer.VirtualH, vlayer.VsrcX, vlayer.VsrcY, vlayer.VsrcW, vlayer.VsrcH)
+
+	for i := range vlayer.Vsurfaces {
+		vsurf := &vlayer.Vsurfaces[i]
+
+		vsurf.VdstX, vsurf.VdstY, vsurf.VdstW, vsurf.VdstH =
+			ula.TransformRect(transform, vlayer.VirtualW, vlayer.VirtualH, vsurf.VdstX, vsurf.VdstY, vsurf.VdstW, vsurf.VdstH)
+	}
+
+	vlayer.VirtualW, vlayer.VirtualH = ula.TransformSize(transform, vlayer.VirtualW, vlayer.VirtualH)
+}
+
 func convToRDisplayCoordinate(sVlayers []ula.VirtualLayer,
 	vdisp *ula.VirtualDisplay, rdisp *ula.RealDisplay) []ula.VirtualLayer {
 
@@ -457,8 +497,8 @@ func convToRDisplayCoordinate(sVlayers []ula.VirtualLayer,
 	vdisp_vw := vdisp.VirtualW
 	vdisp_vh := vdisp.VirtualH
 
-	rdisp_pixw := rdisp.PixelW
-	rdisp_pixh := rdisp.PixelH
+	/* size of the real display before the transform */
+	rdisp_pixw, rdisp_pixh := ula.TransformSize(rdisp.Transform, rdisp.PixelW, rdisp.PixelH)
 
 	for _, vlayer := range sVlayers {
 
@@ -475,6 +515,8 @@ func convToRDisplayCoordinate(sVlayers []ula.VirtualLayer,
 		newVlayer.VdstY = vlayer_vdy * rdisp_pixh / vdisp_vh
 		newVlayer.VdstH = vlayer_vdh * rdisp_pixh / vdisp_vh
 
+		transformVLayer(newVlayer, rdisp.Transform, rdisp_pixw, rdisp_pixh)
+
 		dVlayers = append(dVlayers, *newVlayer)
 	}
 
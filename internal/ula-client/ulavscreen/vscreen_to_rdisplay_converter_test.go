@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulavscreen
+
+import (
+	"reflect"
+	"testing"
+	"ula-tools/internal/ula"
+)
+
+/* only the destinations are transformed, the surface buffers keep their size and source */
+func TestTransformVLayer(t *testing.T) {
+	tests := []struct {
+		transform string
+		layerDst  [4]int
+		layerSize [2]int
+		surfDst   [4]int
+	}{
+		{ula.TRANSFORM_NORMAL, [4]int{0, 0, 1920, 1080}, [2]int{1920, 1080}, [4]int{100, 200, 300, 400}},
+		{ula.TRANSFORM_90, [4]int{0, 0, 1080, 1920}, [2]int{1080, 1920}, [4]int{480, 100, 400, 300}},
+		{ula.TRANSFORM_180, [4]int{0, 0, 1920, 1080}, [2]int{1920, 1080}, [4]int{1520, 480, 300, 400}},
+		{ula.TRANSFORM_FLIPPED_90, [4]int{0, 0, 1080, 1920}, [2]int{1080, 1920}, [4]int{480, 1520, 400, 300}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transform, func(t *testing.T) {
+			vsurf := ula.VirtualSurface{
+				VID: 10, PixelW: 640, PixelH: 480,
+				PsrcX: 0, PsrcY: 0, PsrcW: 640, PsrcH: 480,
+				VdstX: 100, VdstY: 200, VdstW: 300, VdstH: 400,
+			}
+			vlayer := ula.VirtualLayer{
+				VID: 1, VirtualW: 1920, VirtualH: 1080,
+				VsrcW: 1920, VsrcH: 1080, VdstW: 1920, VdstH: 1080,
+				Vsurfaces: []ula.VirtualSurface{vsurf},
+			}
+
+			transformVLayer(&vlayer, tt.transform, 1920, 1080)
+
+			if got := [4]int{vlayer.VdstX, vlayer.VdstY, vlayer.VdstW, vlayer.VdstH}; got != tt.layerDst {
+				t.Errorf("layer dst = %v, want %v", got, tt.layerDst)
+			}
+			if got := [2]int{vlayer.VirtualW, vlayer.VirtualH}; got != tt.layerSize {
+				t.Errorf("layer size = %v, want %v", got, tt.layerSize)
+			}
+			got := vlayer.Vsurfaces[0]
+			if dst := [4]int{got.VdstX, got.VdstY, got.VdstW, got.VdstH}; dst != tt.surfDst {
+				t.Errorf("surface dst = %v, want %v", dst, tt.surfDst)
+			}
+			got.VdstX, got.VdstY, got.VdstW, got.VdstH = vsurf.VdstX, vsurf.VdstY, vsurf.VdstW, vsurf.VdstH
+			if !reflect.DeepEqual(got, vsurf) {
+				t.Errorf("surface buffer is transformed: %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulanode
+
+import (
+	"sync"
+	"time"
+	"ula-tools/internal/ula"
+)
+
+const COMMAND_RECORD_SIZE = 32
+
+/* a command which ula-node received and its result */
+type CommandRecord struct {
+	Time    time.Time
+	Phase   string
+	TxId    uint64
+	Command string
+	ChgIds  []ula.IdPair
+	Result  int
+	Latency time.Duration
+}
+
+var (
+	commandRecordMutex sync.Mutex
+	commandRecords     = make([]CommandRecord, 0)
+)
+
+func RecordCommand(record CommandRecord) {
+	commandRecordMutex.Lock()
+	defer commandRecordMutex.Unlock()
+
+	record.ChgIds = append([]ula.IdPair{}, record.ChgIds...)
+	commandRecords = append(commandRecords, record)
+	if len(commandRecords) > COMMAND_RECORD_SIZE {
+		commandRecords = append([]CommandRecord{}, commandRecords[len(commandRecords)-COMMAND_RECORD_SIZE:]...)
+	}
+}
+
+/* returns the last COMMAND_RECORD_SIZE commands, the oldest first */
+func GetCommandRecords() []CommandRecord {
+	commandRecordMutex.Lock()
+	defer commandRecordMutex.Unlock()
+
+	return append([]CommandRecord{}, commandRecords...)
+}
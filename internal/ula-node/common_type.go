@@ -35,7 +35,26 @@ type LocalCommandReq struct {
 	Ret     int
 }
 
+/* the messages to a compositor approximated by the dry run */
+type CompositorCommand struct {
+	Compositor  string
+	RDisplayIds []int
+	Messages    []string
+}
+
+/* the state which a plugin keeps across the commands, such as the split layer IDs of ivi */
+type GeneratorState interface{}
+
 type LocalCommandGenerator interface {
 	Start(reqChan chan LocalCommandReq, respChan chan LocalCommandReq)
 	GenerateLocalCommandReq(*ula.ApplyCommandData, *ula.NodePixelScreens) ([]*LocalCommandReq, error)
+
+	/*
+	 * Generates the commands on a copy of the generator state, and returns the
+	 * copy. It is put in place by SwapGeneratorState when the command is committed.
+	 */
+	PrepareLocalCommandReq(*ula.ApplyCommandData, *ula.NodePixelScreens) ([]*LocalCommandReq, GeneratorState, error)
+
+	/* puts the state in place and returns the one which it replaces */
+	SwapGeneratorState(GeneratorState) GeneratorState
 }
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulanode
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"ula-tools/internal/ula"
+)
+
+const (
+	RECONNECT_CHECK_INTERVAL = 500 * time.Millisecond
+)
+
+/* connection state of a compositor, RDisplayIds is nil if it has all real displays of the node */
+type CompositorStatus struct {
+	Name             string
+	RDisplayIds      []int
+	Connected        bool
+	Reconnects       int
+	LastResync       time.Time
+	LastResyncResult int
+}
+
+var (
+	compositorStatusMutex sync.Mutex
+	compositorStatuses    = make(map[string]*CompositorStatus)
+
+	appliedMutex      sync.Mutex
+	appliedNPScreens  = new(ula.NodePixelScreens)
+	appliedGeneration uint64
+)
+
+var (
+	timeoutPolicyMutex sync.Mutex
+	timeoutPolicy      = ula.TimeoutPolicy{
+		RetryBackoff:      ula.DEFAULT_RETRY_BACKOFF,
+		CompositorTimeout: ula.DEFAULT_COMPOSITOR_TIMEOUT,
+	}
+)
+
+/* the plugins connect and reconnect to their compositors by the TimeoutPolicy of the node */
+func SetTimeoutPolicy(policy ula.TimeoutPolicy) {
+	timeoutPolicyMutex.Lock()
+	defer timeoutPolicyMutex.Unlock()
+
+	timeoutPolicy = policy
+}
+
+/* the time for a plugin to connect to its compositor */
+func GetCompositorTimeout() time.Duration {
+	timeoutPolicyMutex.Lock()
+	defer timeoutPolicyMutex.Unlock()
+
+	return timeoutPolicy.CompositorTimeout
+}
+
+/* the backoff of the reconnection to a compositor */
+func NewReconnectBackoff() ula.Backoff {
+	timeoutPolicyMutex.Lock()
+	defer timeoutPolicyMutex.Unlock()
+
+	return timeoutPolicy.NewBackoff()
+}
+
+func SetCompositorConnected(name string, rdisplayIds []int, connected bool) {
+	compositorStatusMutex.Lock()
+	defer compositorStatusMutex.Unlock()
+
+	status, ok := compositorStatuses[name]
+	if !ok {
+		status = &CompositorStatus{Name: name}
+		compositorStatuses[name] = status
+	} else if connected && !status.Connected {
+		status.Reconnects++
+	}
+	status.RDisplayIds = append([]int(nil), rdisplayIds...)
+	status.Connected = connected
+}
+
+func SetCompositorResyncResult(name string, result int) {
+	compositorStatusMutex.Lock()
+	defer compositorStatusMutex.Unlock()
+
+	status, ok := compositorStatuses[name]
+	if !ok {
+		return
+	}
+	status.LastResync = time.Now()
+	status.LastResyncResult = result
+}
+
+/* returns the states of all compositors in order of their names */
+func GetCompositorStatuses() []CompositorStatus {
+	compositorStatusMutex.Lock()
+	defer compositorStatusMutex.Unlock()
+
+	statuses := make([]CompositorStatus, 0)
+	for _, status := range compositorStatuses {
+		copied := *status
+		copied.RDisplayIds = append([]int(nil), status.RDisplayIds...)
+		statuses = append(statuses, copied)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+/*
+ * The layout which ula-node applied last and its generation in ula-client.
+ * It is replayed when a compositor reconnects.
+ */
+func SetAppliedLayout(npscrns *ula.NodePixelScreens, generation uint64) {
+	appliedMutex.Lock()
+	defer appliedMutex.Unlock()
+
+	appliedGeneration = generation
+	if npscrns == nil {
+		appliedNPScreens = new(ula.NodePixelScreens)
+		return
+	}
+	appliedNPScreens = npscrns.Dup()
+}
+
+func GetAppliedGeneration() uint64 {
+	appliedMutex.Lock()
+	defer appliedMutex.Unlock()
+
+	return appliedGeneration
+}
+
+/* returns the applied layout of the real displays, or of all real displays if rdisplayIds is nil */
+func GetAppliedNPScreens(rdisplayIds []int) *ula.NodePixelScreens {
+	appliedMutex.Lock()
+	defer appliedMutex.Unlock()
+
+	npscrns := appliedNPScreens.Dup()
+	if rdisplayIds == nil {
+		return npscrns
+	}
+
+	pscrns := make([]ula.PixelScreen, 0)
+	for _, pscrn := range npscrns.Pscreens {
+		for _, rdisplayId := range rdisplayIds {
+			if pscrn.Rdisplay.RDisplayId == rdisplayId {
+				pscrns = append(pscrns, pscrn)
+			}
+		}
+	}
+	npscrns.Pscreens = pscrns
+	return npscrns
+}
+
+/*
+ * Generates the commands which send the whole applied layout of the real
+ * displays to a compositor which has come back empty. It returns no command
+ * if nothing has been applied to them.
+ */
+func GenerateResyncCommandReq(plugin LocalCommandGenerator, rdisplayIds []int) ([]*LocalCommandReq, error) {
+	npscrns := GetAppliedNPScreens(rdisplayIds)
+	if len(npscrns.Pscreens) == 0 {
+		return []*LocalCommandReq{}, nil
+	}
+
+	acdata := &ula.ApplyCommandData{
+		Command:   "initial_vscreen",
+		NPScreens: npscrns,
+	}
+	return plugin.GenerateLocalCommandReq(acdata, new(ula.NodePixelScreens))
+}
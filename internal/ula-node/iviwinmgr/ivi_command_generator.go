@@ -19,6 +19,7 @@ package iviwinmgr
 
 import (
 	"errors"
+	"reflect"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-node"
 )
@@ -33,9 +34,40 @@ type workIvi struct {
 type IviPlugin struct{}
 
 func (plugin IviPlugin) GenerateLocalCommandReq(acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]*ulanode.LocalCommandReq, error) {
+	/*
+	 * The resync of uhmi-ivi-wm calls this concurrently with the command loop,
+	 * so the old and the new layout are split with the same table.
+	 */
+	pLayerSplitMutex.Lock()
+	defer pLayerSplitMutex.Unlock()
+
+	return generateLocalCommandReq(&pLayerSplitIDs, acdata, sps)
+}
+
+/* the split layer ID table of the prepared command is put in place at commit */
+func (plugin IviPlugin) PrepareLocalCommandReq(acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]*ulanode.LocalCommandReq, ulanode.GeneratorState, error) {
+	splitIDs := GetPLayerSplitIDs()
+	reqs, err := generateLocalCommandReq(&splitIDs, acdata, sps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reqs, splitIDs, nil
+}
+
+func (plugin IviPlugin) SwapGeneratorState(state ulanode.GeneratorState) ulanode.GeneratorState {
+	pLayerSplitMutex.Lock()
+	defer pLayerSplitMutex.Unlock()
+
+	prevSplitIDs := pLayerSplitIDs
+	pLayerSplitIDs, _ = state.([]PLayerSplitIdTbl)
+	return prevSplitIDs
+}
+
+/* table is the split layer ID table, which is updated for acdata */
+func generateLocalCommandReq(table *[]PLayerSplitIdTbl, acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]*ulanode.LocalCommandReq, error) {
 	ltqs := []*ulanode.LocalCommandReq{}
 
-	splitOldSps, err := splitLayer(sps.Dup())
+	splitOldSps, err := splitLayer(table, sps.Dup())
 	if err != nil {
 		return nil, errors.New("splitLayer error")
 	}
@@ -44,7 +76,7 @@ func (plugin IviPlugin) GenerateLocalCommandReq(acdata *ula.ApplyCommandData, sp
 		return nil, errors.New("generateWorkIvi error")
 	}
 
-	splitSps, err := splitLayer(acdata.NPScreens.Dup())
+	splitSps, err := splitLayer(table, acdata.NPScreens.Dup())
 	if err != nil {
 		return nil, errors.New("splitLayer error")
 	}
@@ -58,6 +90,12 @@ func (plugin IviPlugin) GenerateLocalCommandReq(acdata *ula.ApplyCommandData, sp
 		ltqs = append(ltqs, ret)
 	}
 
+	rets, err := pickupDiffVScreen(wIviMap, oldwIviMap)
+	if err != nil {
+		return nil, errors.New("pickupDiffVScreen error")
+	}
+	ltqs = append(ltqs, rets...)
+
 	return ltqs, nil
 }
 
@@ -167,3 +205,261 @@ func pickupInitialVScreen(
 
 	return ltq, nil
 }
+
+func findPlayerIndex(players []ula.PixelLayer, layerId int) int {
+	for idx, player := range players {
+		if player.VID == layerId {
+			return idx
+		}
+	}
+	return -1
+}
+
+func findPsurfaceIndex(psurfaces []ula.PixelSurface, surfaceId int) int {
+	for idx, psurface := range psurfaces {
+		if psurface.VID == surfaceId {
+			return idx
+		}
+	}
+	return -1
+}
+
+func dupPixelLayerWithoutSurfaces(player *ula.PixelLayer) *ula.PixelLayer {
+	copied := player.Dup()
+	copied.Psurfaces = make([]ula.PixelSurface, 0)
+	return copied
+}
+
+func isSamePixelLayerProperty(player *ula.PixelLayer, oldPlayer *ula.PixelLayer) bool {
+	return reflect.DeepEqual(dupPixelLayerWithoutSurfaces(player), dupPixelLayerWithoutSurfaces(oldPlayer))
+}
+
+/* returns the VIDs of the layers which keep their relative order (longest common subsequence) */
+func getStablePlayerIds(players []ula.PixelLayer, oldPlayers []ula.PixelLayer) map[int]bool {
+
+	n := len(players)
+	m := len(oldPlayers)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if players[i].VID == oldPlayers[j].VID {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	stableIds := make(map[int]bool)
+	for i, j := 0, 0; i < n && j < m; {
+		if players[i].VID == oldPlayers[j].VID {
+			stableIds[players[i].VID] = true
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return stableIds
+}
+
+/*
+ * The layers in orderedPlayers are inserted from the bottom one by one.
+ * The lowest one is inserted below the lowest placed layer, and the others
+ * are inserted above the layer directly under it, so that every reference
+ * layer is already at the right position when it is referred.
+ */
+func genInsertOrderCommandData(
+	rdisplay *ula.RealDisplay,
+	orderedPlayers []ula.PixelLayer,
+	isTarget func(player *ula.PixelLayer) bool,
+	isPlaced func(player *ula.PixelLayer) bool) ([]ulanode.RdisplayCommandData, error) {
+
+	dcomms := make([]ulanode.RdisplayCommandData, 0)
+
+	for idx, player := range orderedPlayers {
+		if !isTarget(&player) {
+			continue
+		}
+
+		dcomm, err := ulanode.NewRdisplayCommandData(rdisplay, orderedPlayers[idx:idx+1])
+		if err != nil {
+			return nil, err
+		}
+
+		if idx > 0 {
+			dcomm.InsertOrder = "above"
+			dcomm.ReferenceId = orderedPlayers[idx-1].VID
+		} else {
+			for _, refPlayer := range orderedPlayers[1:] {
+				if isPlaced(&refPlayer) {
+					dcomm.InsertOrder = "below"
+					dcomm.ReferenceId = refPlayer.VID
+					break
+				}
+			}
+		}
+
+		dcomms = append(dcomms, *dcomm)
+	}
+
+	return dcomms, nil
+}
+
+func pickupDiffLayers(wIvi *workIvi, oldwIvi *workIvi, dcommsMap map[string][]ulanode.RdisplayCommandData) error {
+
+	var (
+		removedPlayers  []ula.PixelLayer
+		modifiedPlayers []ula.PixelLayer
+		commonPlayers   []ula.PixelLayer
+		oldCommonPlayer []ula.PixelLayer
+	)
+
+	for _, oldPlayer := range oldwIvi.players {
+		if findPlayerIndex(wIvi.players, oldPlayer.VID) < 0 {
+			removedPlayers = append(removedPlayers, *oldPlayer.Dup())
+		} else {
+			oldCommonPlayer = append(oldCommonPlayer, *oldPlayer.Dup())
+		}
+	}
+
+	addedIds := make(map[int]bool)
+	for _, player := range wIvi.players {
+		idx := findPlayerIndex(oldwIvi.players, player.VID)
+		if idx < 0 {
+			addedIds[player.VID] = true
+			continue
+		}
+		commonPlayers = append(commonPlayers, *player.Dup())
+
+		oldPlayer := oldwIvi.players[idx]
+		if !isSamePixelLayerProperty(&player, &oldPlayer) {
+			modifiedPlayers = append(modifiedPlayers, *dupPixelLayerWithoutSurfaces(&player))
+		}
+
+		var removed, added, modified []ula.PixelSurface
+		for _, oldPsurf := range oldPlayer.Psurfaces {
+			if findPsurfaceIndex(player.Psurfaces, oldPsurf.VID) < 0 {
+				removed = append(removed, oldPsurf)
+			}
+		}
+		for _, psurf := range player.Psurfaces {
+			sidx := findPsurfaceIndex(oldPlayer.Psurfaces, psurf.VID)
+			if sidx < 0 {
+				added = append(added, psurf)
+			} else if !reflect.DeepEqual(psurf, oldPlayer.Psurfaces[sidx]) {
+				modified = append(modified, psurf)
+			}
+		}
+
+		for lcomm, psurfs := range map[string][]ula.PixelSurface{
+			"remove_surface": removed,
+			"add_surface":    added,
+			"modify_surface": modified,
+		} {
+			if len(psurfs) == 0 {
+				continue
+			}
+			splayer := dupPixelLayerWithoutSurfaces(&player)
+			splayer.Psurfaces = psurfs
+			dcomm, err := ulanode.NewRdisplayCommandData(&wIvi.rdisplay, []ula.PixelLayer{*splayer})
+			if err != nil {
+				return err
+			}
+			dcommsMap[lcomm] = append(dcommsMap[lcomm], *dcomm)
+		}
+	}
+
+	if len(removedPlayers) != 0 {
+		dcomm, err := ulanode.NewRdisplayCommandData(&wIvi.rdisplay, removedPlayers)
+		if err != nil {
+			return err
+		}
+		dcommsMap["remove_layer"] = append(dcommsMap["remove_layer"], *dcomm)
+	}
+
+	if len(modifiedPlayers) != 0 {
+		dcomm, err := ulanode.NewRdisplayCommandData(&wIvi.rdisplay, modifiedPlayers)
+		if err != nil {
+			return err
+		}
+		dcommsMap["modify_layer"] = append(dcommsMap["modify_layer"], *dcomm)
+	}
+
+	/* restack the existing layers before adding new layers on them */
+	stableIds := getStablePlayerIds(commonPlayers, oldCommonPlayer)
+	dcomms, err := genInsertOrderCommandData(&wIvi.rdisplay, commonPlayers,
+		func(player *ula.PixelLayer) bool { return !stableIds[player.VID] },
+		func(player *ula.PixelLayer) bool { return stableIds[player.VID] })
+	if err != nil {
+		return err
+	}
+	dcommsMap["restack_layer"] = append(dcommsMap["restack_layer"], dcomms...)
+
+	dcomms, err = genInsertOrderCommandData(&wIvi.rdisplay, wIvi.players,
+		func(player *ula.PixelLayer) bool { return addedIds[player.VID] },
+		func(player *ula.PixelLayer) bool { return !addedIds[player.VID] })
+	if err != nil {
+		return err
+	}
+	dcommsMap["add_layer"] = append(dcommsMap["add_layer"], dcomms...)
+
+	return nil
+}
+
+/* generate differential commands for the real displays which already have layers */
+func pickupDiffVScreen(
+	wIviMap map[int]workIvi,
+	oldwIviMap map[int]workIvi) ([]*ulanode.LocalCommandReq, error) {
+
+	ltqs := []*ulanode.LocalCommandReq{}
+	dcommsMap := make(map[string][]ulanode.RdisplayCommandData)
+
+	for key, wIvi := range wIviMap {
+		oldwIvi, ok := oldwIviMap[key]
+		if !ok || len(oldwIvi.players) == 0 {
+			continue
+		}
+
+		err := pickupDiffLayers(&wIvi, &oldwIvi, dcommsMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	/* the order to be sent to uhmi-ivi-wm */
+	lcomms := []string{
+		"remove_layer",
+		"restack_layer",
+		"add_layer",
+		"modify_layer",
+		"remove_surface",
+		"add_surface",
+		"modify_surface",
+	}
+
+	for _, lcomm := range lcomms {
+		if len(dcommsMap[lcomm]) == 0 {
+			continue
+		}
+
+		ltq, err := ulanode.NewEmptyLocalCommandReq()
+		if err != nil {
+			return nil, err
+		}
+		ltq.Command = lcomm
+		ltq.RDComms = dcommsMap[lcomm]
+
+		ltqs = append(ltqs, ltq)
+	}
+
+	return ltqs, nil
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iviwinmgr
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"ula-tools/internal/ula"
+	"ula-tools/internal/ula-node"
+)
+
+func testPsurface(vid int, x int) ula.PixelSurface {
+	return ula.PixelSurface{
+		VID: vid, PixelW: 640, PixelH: 480,
+		PsrcW: 640, PsrcH: 480,
+		PdstX: x, PdstW: 640, PdstH: 480,
+		Visibility: 1, Opacity: 1,
+	}
+}
+
+func testPlayer(vid int, x int, psurfaces ...ula.PixelSurface) ula.PixelLayer {
+	return ula.PixelLayer{
+		VID: vid, PixelW: 1920, PixelH: 1080,
+		PsrcW: 1920, PsrcH: 1080,
+		PdstX: x, PdstW: 1920, PdstH: 1080,
+		Visibility: 1, Opacity: 1,
+		Psurfaces: psurfaces,
+	}
+}
+
+/* summarizes the commands as "<layer VID>[/<surface VID>][ above|below <reference VID>]" */
+func summarizeDcommsMap(dcommsMap map[string][]ulanode.RdisplayCommandData) map[string][]string {
+	summary := make(map[string][]string)
+	for lcomm, dcomms := range dcommsMap {
+		for _, dcomm := range dcomms {
+			for _, player := range dcomm.Players {
+				if len(player.Psurfaces) == 0 || lcomm == "remove_layer" || lcomm == "restack_layer" || lcomm == "add_layer" {
+					s := fmt.Sprint(player.VID)
+					if dcomm.InsertOrder != "" {
+						s += fmt.Sprintf(" %s %d", dcomm.InsertOrder, dcomm.ReferenceId)
+					}
+					summary[lcomm] = append(summary[lcomm], s)
+					continue
+				}
+				for _, psurf := range player.Psurfaces {
+					summary[lcomm] = append(summary[lcomm], fmt.Sprintf("%d/%d", player.VID, psurf.VID))
+				}
+			}
+		}
+	}
+	return summary
+}
+
+func TestPickupDiffLayers(t *testing.T) {
+	base := []ula.PixelLayer{
+		testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+		testPlayer(200, 0, testPsurface(20, 0)),
+		testPlayer(300, 0),
+	}
+
+	tests := []struct {
+		name    string
+		players []ula.PixelLayer
+		want    map[string][]string
+	}{
+		{
+			name:    "no change",
+			players: base,
+			want:    map[string][]string{},
+		},
+		{
+			name: "layer moved",
+			players: []ula.PixelLayer{
+				testPlayer(100, 100, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(200, 0, testPsurface(20, 0)),
+				testPlayer(300, 0),
+			},
+			want: map[string][]string{"modify_layer": {"100"}},
+		},
+		{
+			name: "surfaces added, removed and modified",
+			players: []ula.PixelLayer{
+				testPlayer(100, 0, testPsurface(11, 320), testPsurface(12, 0)),
+				testPlayer(200, 0, testPsurface(20, 0)),
+				testPlayer(300, 0),
+			},
+			want: map[string][]string{
+				"remove_surface": {"100/10"},
+				"add_surface":    {"100/12"},
+				"modify_surface": {"100/11"},
+			},
+		},
+		{
+			name: "layer removed",
+			players: []ula.PixelLayer{
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(300, 0),
+			},
+			want: map[string][]string{"remove_layer": {"200"}},
+		},
+		{
+			name: "layer added on top",
+			players: []ula.PixelLayer{
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(200, 0, testPsurface(20, 0)),
+				testPlayer(300, 0),
+				testPlayer(400, 0),
+			},
+			want: map[string][]string{"add_layer": {"400 above 300"}},
+		},
+		{
+			name: "layer added at the bottom",
+			players: []ula.PixelLayer{
+				testPlayer(400, 0),
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(200, 0, testPsurface(20, 0)),
+				testPlayer(300, 0),
+			},
+			want: map[string][]string{"add_layer": {"400 below 100"}},
+		},
+		{
+			name: "top layer moved to the bottom",
+			players: []ula.PixelLayer{
+				testPlayer(300, 0),
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(200, 0, testPsurface(20, 0)),
+			},
+			want: map[string][]string{"restack_layer": {"300 below 100"}},
+		},
+		{
+			name: "bottom layer moved to the top",
+			players: []ula.PixelLayer{
+				testPlayer(200, 0, testPsurface(20, 0)),
+				testPlayer(300, 0),
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+			},
+			want: map[string][]string{"restack_layer": {"100 above 300"}},
+		},
+		{
+			name: "layer replaced",
+			players: []ula.PixelLayer{
+				testPlayer(100, 0, testPsurface(10, 0), testPsurface(11, 640)),
+				testPlayer(400, 0),
+				testPlayer(300, 0),
+			},
+			want: map[string][]string{
+				"remove_layer": {"200"},
+				"add_layer":    {"400 above 100"},
+			},
+		},
+	}
+
+	rdisplay := ula.RealDisplay{RDisplayId: 0, PixelW: 1920, PixelH: 1080}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldwIvi := &workIvi{rdisplay: rdisplay, players: base}
+			wIvi := &workIvi{rdisplay: rdisplay, players: tt.players}
+
+			dcommsMap := make(map[string][]ulanode.RdisplayCommandData)
+			if err := pickupDiffLayers(wIvi, oldwIvi, dcommsMap); err != nil {
+				t.Fatal(err)
+			}
+			if got := summarizeDcommsMap(dcommsMap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commands = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@
 package iviwinmgr
 
 import (
+	"sync"
 	"ula-tools/internal/ula"
 )
 
@@ -28,6 +29,30 @@ type PLayerSplitIdTbl struct {
 }
 
 var pLayerSplitIDs []PLayerSplitIdTbl
+var pLayerSplitMutex sync.Mutex
+
+/* returns a copy of the split layer ID table */
+func GetPLayerSplitIDs() []PLayerSplitIdTbl {
+	pLayerSplitMutex.Lock()
+	defer pLayerSplitMutex.Unlock()
+
+	return dupPLayerSplitIDs(pLayerSplitIDs)
+}
+
+func dupPLayerSplitIDs(table []PLayerSplitIdTbl) []PLayerSplitIdTbl {
+	splitIDs := make([]PLayerSplitIdTbl, 0)
+	for _, splitID := range table {
+		copied := PLayerSplitIdTbl{
+			RDisplayId: splitID.RDisplayId,
+			IdPair:     make(map[int]int),
+		}
+		for layerVID, newID := range splitID.IdPair {
+			copied.IdPair[layerVID] = newID
+		}
+		splitIDs = append(splitIDs, copied)
+	}
+	return splitIDs
+}
 
 func makeDiffPLayerSplitTbl(diffPLayerSplitIDs *[]PLayerSplitIdTbl, rDisplayId int, layerVID int, newID int) {
 
@@ -50,9 +75,9 @@ func makeDiffPLayerSplitTbl(diffPLayerSplitIDs *[]PLayerSplitIdTbl, rDisplayId i
 }
 
 func genSplitLayerID(
-	spscrns *ula.NodePixelScreens, layerVID int, rDisplayId int, diffPLayerSplitIDs *[]PLayerSplitIdTbl) int {
+	table *[]PLayerSplitIdTbl, spscrns *ula.NodePixelScreens, layerVID int, rDisplayId int, diffPLayerSplitIDs *[]PLayerSplitIdTbl) int {
 
-	for _, splitID := range pLayerSplitIDs {
+	for _, splitID := range *table {
 		if splitID.RDisplayId == rDisplayId {
 			if v, ok := splitID.IdPair[layerVID]; ok {
 				makeDiffPLayerSplitTbl(diffPLayerSplitIDs, rDisplayId, layerVID, splitID.IdPair[layerVID])
@@ -73,7 +98,7 @@ RETRY:
 		}
 	}
 
-	for _, splitID := range pLayerSplitIDs {
+	for _, splitID := range *table {
 		if _, ok := splitID.IdPair[newID]; ok {
 			newID++
 			goto RETRY
@@ -86,7 +111,7 @@ RETRY:
 		}
 	}
 
-	for _, splitID := range pLayerSplitIDs {
+	for _, splitID := range *table {
 		if splitID.RDisplayId == rDisplayId {
 			splitID.IdPair[layerVID] = newID
 			makeDiffPLayerSplitTbl(diffPLayerSplitIDs, rDisplayId, layerVID, newID)
@@ -98,7 +123,7 @@ RETRY:
 		IdPair:     make(map[int]int),
 	}
 	pLayerSplitID.IdPair[layerVID] = newID
-	pLayerSplitIDs = append(pLayerSplitIDs, pLayerSplitID)
+	*table = append(*table, pLayerSplitID)
 
 	makeDiffPLayerSplitTbl(diffPLayerSplitIDs, rDisplayId, layerVID, newID)
 
@@ -106,7 +131,7 @@ RETRY:
 }
 
 func splitIviLayer(
-	spscrns *ula.NodePixelScreens) (*ula.NodePixelScreens, error) {
+	table *[]PLayerSplitIdTbl, spscrns *ula.NodePixelScreens) (*ula.NodePixelScreens, error) {
 
 	diffPLayerSplitIDs := make([]PLayerSplitIdTbl, 0)
 
@@ -138,7 +163,7 @@ func splitIviLayer(
 
 					if layerVID == player.VID {
 						player.VID =
-							genSplitLayerID(spscrns, layerVID, pscrn.Rdisplay.RDisplayId, &diffPLayerSplitIDs)
+							genSplitLayerID(table, spscrns, layerVID, pscrn.Rdisplay.RDisplayId, &diffPLayerSplitIDs)
 						pscrn.Players[i] = player
 					}
 				}
@@ -147,14 +172,17 @@ func splitIviLayer(
 		}
 	}
 
-	pLayerSplitIDs = diffPLayerSplitIDs
+	*table = diffPLayerSplitIDs
 	return spscrns, nil
 
 }
 
-func splitLayer(srvPixScreens *ula.NodePixelScreens) (*ula.NodePixelScreens, error) {
-
-	dpscrns, err := splitIviLayer(srvPixScreens)
+/*
+ * table is the split layer ID table which is updated by the split, it is
+ * pLayerSplitIDs under pLayerSplitMutex or a private copy of it.
+ */
+func splitLayer(table *[]PLayerSplitIdTbl, srvPixScreens *ula.NodePixelScreens) (*ula.NodePixelScreens, error) {
+	dpscrns, err := splitIviLayer(table, srvPixScreens)
 	if err != nil {
 		return nil, err
 	}
@@ -32,59 +32,36 @@ import (
 	. "ula-tools/internal/ulog"
 )
 
+/* conn and waitChan are set by the goroutine of the connection and guarded by connMutex */
 type iviWinMgr struct {
-	conn     net.Conn
-	sendChan chan string
-	waitChan chan []byte
-	recvChan chan []byte
+	conn      net.Conn
+	waitChan  chan []byte
+	connMutex sync.Mutex
 }
 
-func retryConnectTarget(sockChan chan net.Conn, stopChan chan struct{}) {
+/* retries the dial until the compositor timeout */
+func connectTarget() net.Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), ulanode.GetCompositorTimeout())
+	defer cancel()
+
+	var dialer net.Dialer
 	for {
-		conn, err := net.Dial("unix", UHMI_IVI_WM_SOCK)
+		conn, err := dialer.DialContext(ctx, "unix", UHMI_IVI_WM_SOCK)
 		if err == nil {
-			sockChan <- conn
-			break
+			ILog.Println("Dial connected to uhmi-ivi-wm")
+			DLog.Printf("connect OK\n")
+			return conn
 		}
 
-		time.Sleep(10 * time.Millisecond)
-
 		select {
-		case <-stopChan:
-			return
-		default:
+		case <-ctx.Done():
+			ELog.Println("Dial cannot connect to uhmi-ivi-wm")
+			return nil
+		case <-time.After(10 * time.Millisecond):
 		}
 	}
 }
 
-func connectTarget() net.Conn {
-	var conn net.Conn
-
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-
-	sockChan := make(chan net.Conn, 1)
-	stopChan := make(chan struct{})
-
-	defer func() {
-		close(sockChan)
-		close(stopChan)
-	}()
-
-	go retryConnectTarget(sockChan, stopChan)
-
-	select {
-	case <-ctx.Done():
-		ELog.Println("Dial cannot connect to uhmi-ivi-wm")
-		return nil
-	case conn = <-sockChan:
-		ILog.Println("Dial connected to uhmi-ivi-wm")
-	}
-
-	DLog.Printf("connect OK\n")
-	return conn
-}
-
 func connectTargetOnce() net.Conn {
 	var conn net.Conn
 
@@ -100,44 +77,65 @@ func connectTargetOnce() net.Conn {
 
 func handleConnectTarget(iviwinmgr *iviWinMgr, isretry bool, wg *sync.WaitGroup) {
 
+	var conn net.Conn
 	if isretry == true {
-		iviwinmgr.conn = connectTarget()
+		conn = connectTarget()
 	} else {
-		iviwinmgr.conn = connectTargetOnce()
+		conn = connectTargetOnce()
 	}
 
-	if iviwinmgr.conn == nil {
+	if conn == nil {
 		wg.Done()
 		return
 	}
-	iviwinmgr.waitChan = make(chan []byte, 1)
-	iviwinmgr.sendChan = make(chan string, 1)
-	iviwinmgr.recvChan = make(chan []byte, 1)
+	waitChan := make(chan []byte, 1)
+	recvChan := make(chan []byte, 1)
+	iviwinmgr.setConn(conn, waitChan)
 
 	defer func() {
-		iviwinmgr.conn.Close()
-		iviwinmgr.conn = nil
-		close(iviwinmgr.waitChan)
-		close(iviwinmgr.sendChan)
-		close(iviwinmgr.recvChan)
+		iviwinmgr.setConn(nil, nil)
+		conn.Close()
+		close(waitChan)
+		close(recvChan)
 	}()
 
-	go connReadLoop(iviwinmgr.conn, iviwinmgr.recvChan)
+	go connReadLoop(conn, recvChan)
 	wg.Done()
 
 	for {
 		select {
-		case recvMsg := <-iviwinmgr.recvChan:
+		case recvMsg := <-recvChan:
 			if recvMsg != nil {
-				iviwinmgr.waitChan <- recvMsg
+				waitChan <- recvMsg
 			} else {
-				iviwinmgr.waitChan <- nil
+				waitChan <- nil
 				return
 			}
 		}
 	}
 }
 
+func (iviwinmgr *iviWinMgr) setConn(conn net.Conn, waitChan chan []byte) {
+	iviwinmgr.connMutex.Lock()
+	defer iviwinmgr.connMutex.Unlock()
+
+	iviwinmgr.conn = conn
+	iviwinmgr.waitChan = waitChan
+}
+
+/* conn is nil if uhmi-ivi-wm is not connected, and waitChan is closed when the connection is lost */
+func (iviwinmgr *iviWinMgr) getConn() (net.Conn, chan []byte) {
+	iviwinmgr.connMutex.Lock()
+	defer iviwinmgr.connMutex.Unlock()
+
+	return iviwinmgr.conn, iviwinmgr.waitChan
+}
+
+func (iviwinmgr *iviWinMgr) isConnected() bool {
+	conn, _ := iviwinmgr.getConn()
+	return conn != nil
+}
+
 func (plugin IviPlugin) Start(reqChan chan ulanode.LocalCommandReq, respChan chan ulanode.LocalCommandReq) {
 
 	var wg sync.WaitGroup
@@ -147,35 +145,112 @@ func (plugin IviPlugin) Start(reqChan chan ulanode.LocalCommandReq, respChan cha
 	go handleConnectTarget(&iviwinmgr, isRetry, &wg)
 	wg.Wait()
 
+	connected := plugin.updateConnection(&iviwinmgr, false)
+
+	backoff := ulanode.NewReconnectBackoff()
+	ticker := time.NewTicker(ulanode.RECONNECT_CHECK_INTERVAL)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case wVDsp := <-reqChan:
-			if iviwinmgr.conn == nil {
+			if !iviwinmgr.isConnected() {
 				wg.Add(1)
 				isRetry = false
 				go handleConnectTarget(&iviwinmgr, isRetry, &wg)
 				wg.Wait()
 			}
+			connected = plugin.updateConnection(&iviwinmgr, connected)
 
 			ret := sendUhmiIviWmJson(&iviwinmgr, wVDsp)
 			lcr := ulanode.LocalCommandReq{}
 			lcr.Ret = ret
 			respChan <- lcr
 			break
+
+		case <-ticker.C:
+			connected = plugin.updateConnection(&iviwinmgr, connected)
+			if iviwinmgr.isConnected() || !backoff.IsDue() {
+				break
+			}
+
+			/* uhmi-ivi-wm is reconnected without waiting for the next request */
+			wg.Add(1)
+			isRetry = false
+			go handleConnectTarget(&iviwinmgr, isRetry, &wg)
+			wg.Wait()
+			if !iviwinmgr.isConnected() {
+				backoff.Failed()
+				break
+			}
+			backoff.Reset()
+			connected = plugin.updateConnection(&iviwinmgr, connected)
 		}
 	}
 
 }
 
-func sendMagicCode(iviwinmgr *iviWinMgr) error {
+/*
+ * Tracks the connection to uhmi-ivi-wm. A restarted uhmi-ivi-wm comes back
+ * empty, so the whole applied layout is replayed when it is connected again.
+ */
+func (plugin IviPlugin) updateConnection(iviwinmgr *iviWinMgr, connected bool) bool {
 
-	n, err := iviwinmgr.conn.Write(MAGIC_CODE)
+	isConnected := iviwinmgr.isConnected()
+	if connected && !isConnected {
+		WLog.Println("Disconnected from uhmi-ivi-wm")
+		ulanode.SetCompositorConnected(UHMI_IVI_WM_SOCK, nil, false)
+		return false
+	}
+
+	if !connected && isConnected {
+		ulanode.SetCompositorConnected(UHMI_IVI_WM_SOCK, nil, true)
+		plugin.resync(iviwinmgr)
+		return true
+	}
+
+	if !connected {
+		ulanode.SetCompositorConnected(UHMI_IVI_WM_SOCK, nil, false)
+	}
+	return connected
+}
+
+func (plugin IviPlugin) resync(iviwinmgr *iviWinMgr) {
+
+	reqs, err := ulanode.GenerateResyncCommandReq(plugin, nil)
+	if err != nil {
+		ELog.Printf("Resync uhmi-ivi-wm error: %s", err)
+		ulanode.SetCompositorResyncResult(UHMI_IVI_WM_SOCK, -1)
+		return
+	}
+	if len(reqs) == 0 {
+		return
+	}
+
+	ret := 0
+	for _, req := range reqs {
+		if r := sendUhmiIviWmJson(iviwinmgr, *req); r != 0 {
+			ret = r
+		}
+	}
+
+	if ret != 0 {
+		ELog.Printf("Resync uhmi-ivi-wm failed: %d", ret)
+	} else {
+		ILog.Println("Resync uhmi-ivi-wm: the applied layout is replayed")
+	}
+	ulanode.SetCompositorResyncResult(UHMI_IVI_WM_SOCK, ret)
+}
+
+func sendMagicCode(conn net.Conn, waitChan chan []byte) error {
+
+	n, err := conn.Write(MAGIC_CODE)
 	if err != nil || n == 0 {
 		return errors.New(fmt.Sprintf("Write error: %s \n", err))
 	}
 
 	select {
-	case result := <-iviwinmgr.waitChan:
+	case result := <-waitChan:
 		if result != nil {
 			if reflect.DeepEqual(result, MAGIC_CODE) == false {
 				return errors.New(fmt.Sprintf("Read magic code false: %s\n", result))
@@ -191,36 +266,26 @@ func sendUhmiIviWmJson(iviwinmgr *iviWinMgr, req ulanode.LocalCommandReq) int {
 
 	DLog.Println("sendUhmiIviWmJson's reqCommand:", req.Command)
 
-	msg := ""
-	var err error
-
-	switch req.Command {
-	case "initial_vscreen":
-		msg, err = genInitialScreenProtocolJson(req)
-	case "local_comm":
-		return 0
-	default:
-		ELog.Println("Error req.Command")
-		return -1
-	}
+	msg, err := genProtocolJson(req)
 	if err != nil {
-		ELog.Println("Error ProtocolJson")
+		ELog.Println("Error ProtocolJson: ", err)
 		return -1
 	}
 
-	if iviwinmgr.conn == nil {
+	conn, waitChan := iviwinmgr.getConn()
+	if conn == nil {
 		ELog.Printf("Error Not connected to uhmi-ivi-wm")
 		return -1
 	}
 
-	err = sendMagicCode(iviwinmgr)
+	err = sendMagicCode(conn, waitChan)
 	if err != nil {
 		ELog.Printf("Error SendRecv MagicCode: %s", err)
 		return -1
 	}
 
 	DLog.Println("sendCommand", req)
-	err = sendCommand(iviwinmgr, msg)
+	err = sendCommand(conn, waitChan, msg)
 	if err != nil {
 		ELog.Printf("Error SendCommand: %s", err)
 		return -1
@@ -230,24 +295,24 @@ func sendUhmiIviWmJson(iviwinmgr *iviWinMgr, req ulanode.LocalCommandReq) int {
 
 }
 
-func sendCommand(iviwinmgr *iviWinMgr, command string) error {
+func sendCommand(conn net.Conn, waitChan chan []byte, command string) error {
 
 	msgLen := uint32(len(command))
 	size := make([]byte, 4)
 	binary.BigEndian.PutUint32(size, msgLen)
 
-	n, err := iviwinmgr.conn.Write(size)
+	n, err := conn.Write(size)
 	if err != nil || n == 0 {
 		ELog.Printf("Write DATA Size error: %s \n", err)
 	}
 
-	n, err = iviwinmgr.conn.Write([]byte(command))
+	n, err = conn.Write([]byte(command))
 	if err != nil || n == 0 {
 		ELog.Printf("Write error: %s \n", err)
 	}
 
 	select {
-	case result := <-iviwinmgr.waitChan:
+	case result := <-waitChan:
 		if result != nil {
 			ret := binary.BigEndian.Uint32(result)
 			DLog.Printf("Read uhmi-ivi-wm ret: %x", ret)
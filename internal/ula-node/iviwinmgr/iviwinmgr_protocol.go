@@ -19,6 +19,10 @@ package iviwinmgr
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-node"
 	. "ula-tools/internal/ulog"
 )
@@ -28,7 +32,6 @@ const UHMI_IVI_WM_SOCK string = "/tmp/uhmi-ivi-wm_sock"
 var MAGIC_CODE []byte = []byte{0x55, 0x4C, 0x41, 0x30} // 'ULA0' ascii code
 
 const VERSION string = "1.0.0"
-const OPACITY float64 = 1.0
 const VISIBILITY int = 1
 
 type IviSurfaceJson struct {
@@ -64,20 +67,47 @@ type IviLayerJson struct {
 	Surface    []IviSurfaceJson `json:"surfaces"`
 }
 
+/*
+ * The destinations in the layers are already transformed by "transform", and the
+ * surface sources stay in the coordinates of the surface buffers, so the
+ * compositor only transforms the content of each surface.
+ */
 type IviRDisplay struct {
 	RDisplayId  int            `json:"id"`
 	InsertOrder string         `json:"insert_order"`
 	ReferenceId int            `json:"referenceID"`
+	Transform   string         `json:"transform"`
 	Layers      []IviLayerJson `json:"layers"`
 }
 
-type InitialScreenProtocol struct {
+type ScreenProtocol struct {
 	Version  string        `json:"version"`
 	Command  string        `json:"command"`
 	RDisplay []IviRDisplay `json:"screens"`
 }
 
+/* generates the message to uhmi-ivi-wm for the command */
+func genProtocolJson(req ulanode.LocalCommandReq) (string, error) {
+	switch req.Command {
+	case "initial_vscreen":
+		return genInitialScreenProtocolJson(req)
+	case "remove_layer", "restack_layer", "add_layer", "modify_layer",
+		"remove_surface", "add_surface", "modify_surface":
+		return genLayoutDiffProtocolJson(req)
+	default:
+		return "", errors.New(fmt.Sprintf("unknown command: %s", req.Command))
+	}
+}
+
 func genInitialScreenProtocolJson(req ulanode.LocalCommandReq) (string, error) {
+	return genScreenProtocolJson(req, "initial_screen")
+}
+
+func genLayoutDiffProtocolJson(req ulanode.LocalCommandReq) (string, error) {
+	return genScreenProtocolJson(req, req.Command)
+}
+
+func genScreenProtocolJson(req ulanode.LocalCommandReq, command string) (string, error) {
 	var iviRDisp []IviRDisplay
 
 	for _, rdcomm := range req.RDComms {
@@ -102,7 +132,7 @@ func genInitialScreenProtocolJson(req ulanode.LocalCommandReq) (string, error) {
 					DstY:       psurf.PdstY,
 					DstW:       psurf.PdstW,
 					DstH:       psurf.PdstH,
-					Opacity:    OPACITY,
+					Opacity:    psurf.Opacity,
 					Visibility: psurf.Visibility,
 				}
 
@@ -121,7 +151,7 @@ func genInitialScreenProtocolJson(req ulanode.LocalCommandReq) (string, error) {
 				DstY:       player.PdstY,
 				DstW:       player.PdstW,
 				DstH:       player.PdstH,
-				Opacity:    OPACITY,
+				Opacity:    player.Opacity,
 				Visibility: player.Visibility,
 				Surface:    ivisurfs,
 			}
@@ -130,16 +160,19 @@ func genInitialScreenProtocolJson(req ulanode.LocalCommandReq) (string, error) {
 		}
 
 		rdisp := IviRDisplay{
-			RDisplayId: rdcomm.Rdisplay.RDisplayId,
-			Layers:     ivilayers,
+			RDisplayId:  rdcomm.Rdisplay.RDisplayId,
+			InsertOrder: rdcomm.InsertOrder,
+			ReferenceId: rdcomm.ReferenceId,
+			Transform:   ula.NormalizeTransform(rdcomm.Rdisplay.Transform),
+			Layers:      ivilayers,
 		}
 
 		iviRDisp = append(iviRDisp, rdisp)
 	}
 
-	iviProto := InitialScreenProtocol{
+	iviProto := ScreenProtocol{
 		Version:  VERSION,
-		Command:  "initial_screen",
+		Command:  command,
 		RDisplay: iviRDisp,
 	}
 
@@ -152,3 +185,38 @@ func genInitialScreenProtocolJson(req ulanode.LocalCommandReq) (string, error) {
 
 	return msg, nil
 }
+
+/*
+ * Generates an approximation of the messages to uhmi-ivi-wm for acdata without
+ * sending them. sps is the layout which has been applied to the node. The
+ * split layer IDs are generated from sps with an empty table, so they can
+ * differ from the ones of ula-node, which keeps its table across the commands.
+ */
+func GenerateDryRunCommands(acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]ulanode.CompositorCommand, error) {
+	splitIDs := make([]PLayerSplitIdTbl, 0)
+	reqs, err := generateLocalCommandReq(&splitIDs, acdata, sps)
+	if err != nil {
+		return nil, err
+	}
+
+	rdisplayIds := make([]int, 0)
+	for _, pscrn := range acdata.NPScreens.Pscreens {
+		rdisplayIds = append(rdisplayIds, pscrn.Rdisplay.RDisplayId)
+	}
+	sort.Ints(rdisplayIds)
+
+	comm := ulanode.CompositorCommand{
+		Compositor:  UHMI_IVI_WM_SOCK,
+		RDisplayIds: rdisplayIds,
+		Messages:    make([]string, 0),
+	}
+	for _, req := range reqs {
+		msg, err := genProtocolJson(*req)
+		if err != nil {
+			return nil, err
+		}
+		comm.Messages = append(comm.Messages, msg)
+	}
+
+	return []ulanode.CompositorCommand{comm}, nil
+}
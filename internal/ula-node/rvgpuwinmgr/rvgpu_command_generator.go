@@ -19,6 +19,7 @@ package rvgpuwinmgr
 
 import (
 	"errors"
+	"reflect"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-node"
 )
@@ -54,9 +55,27 @@ func (plugin RvgpuPlugin) GenerateLocalCommandReq(acdata *ula.ApplyCommandData,
 		ltqs = append(ltqs, ret)
 	}
 
+	ret, err = pickupUpdateVScreen(wRvgpuMap, oldwRvgpuMap)
+	if err != nil {
+		return ltqs, errors.New("pickupUpdateVScreen error")
+	}
+	if ret != nil {
+		ltqs = append(ltqs, ret)
+	}
+
 	return ltqs, nil
 }
 
+/* the sent layouts of rvgpu are updated when the messages are sent, so it has no generator state */
+func (plugin RvgpuPlugin) PrepareLocalCommandReq(acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]*ulanode.LocalCommandReq, ulanode.GeneratorState, error) {
+	reqs, err := plugin.GenerateLocalCommandReq(acdata, sps)
+	return reqs, nil, err
+}
+
+func (plugin RvgpuPlugin) SwapGeneratorState(state ulanode.GeneratorState) ulanode.GeneratorState {
+	return nil
+}
+
 func generateWorkRvgpu(
 	spscrns *ula.NodePixelScreens) (map[int]workRvgpu, error) {
 
@@ -170,3 +189,43 @@ func pickupInitialVScreen(
 
 	return ltq, nil
 }
+
+/* pick up the real displays whose layout is changed from the current one */
+func pickupUpdateVScreen(
+	wRvgpuMap map[int]workRvgpu,
+	oldwRvgpuMap map[int]workRvgpu) (*ulanode.LocalCommandReq, error) {
+
+	dcomms := make([]ulanode.RdisplayCommandData, 0)
+
+	for key, wRvgpu := range wRvgpuMap {
+		oldwRvgpu, ok := oldwRvgpuMap[key]
+		if !ok || len(oldwRvgpu.players) == 0 {
+			continue
+		}
+
+		if reflect.DeepEqual(wRvgpu.players, oldwRvgpu.players) &&
+			reflect.DeepEqual(wRvgpu.psafetyareas, oldwRvgpu.psafetyareas) {
+			continue
+		}
+
+		/* the whole layout of the display is passed, and the diff is taken against the sent layouts */
+		dcomm, err := ulanode.NewRdisplayCommandDataWithSafetyArea(&wRvgpu.rdisplay, wRvgpu.players, wRvgpu.psafetyareas)
+		if err != nil {
+			return nil, err
+		}
+		dcomms = append(dcomms, *dcomm)
+	}
+
+	if len(dcomms) == 0 {
+		return nil, nil
+	}
+
+	ltq, err := ulanode.NewEmptyLocalCommandReq()
+	if err != nil {
+		return nil, err
+	}
+	ltq.Command = "update_layout"
+	ltq.RDComms = dcomms
+
+	return ltq, nil
+}
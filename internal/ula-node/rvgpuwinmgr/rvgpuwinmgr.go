@@ -33,37 +33,76 @@ import (
 	. "ula-tools/internal/ulog"
 )
 
+/* the reply of rvgpu compositor to each layout message */
+const LAYOUT_COMPLETE string = "Layout complete"
+
+/*
+ * conn, sendChan and waitChan are set by the goroutine of the connection and
+ * guarded by connMutex, which is shared by the copies of the compositor.
+ */
 type rvgpuCompositor struct {
 	rId        int
 	conn       net.Conn
 	sendChan   chan string
 	waitChan   chan bool
-	recvChan   chan []byte
 	domainName string
+	connMutex  *sync.Mutex
 }
 
 var rvgpuComs = make([]rvgpuCompositor, 0)
 
-func IsRvgpuCompositor(
+/*
+ * the rvgpu compositors of the node defined in the compositor section.
+ * If a vdisplay is mirrored to several rdisplays of the node, the compositors
+ * of the vdisplay are assigned to its rdisplays in the order of real_displays.
+ */
+func newRvgpuCompositors(
 	vscrnDef *ula.VScrnDef,
-	nodeId int) bool {
+	nodeId int) []rvgpuCompositor {
 
+	compositors := make([]rvgpuCompositor, 0)
 	for _, fwn := range vscrnDef.DistributedWindowSystem.FrameworkNode {
 		if fwn.NodeId == nodeId {
+			assigned := make(map[int]int)
 			for _, com := range fwn.Compositor {
+				vDisplayId := com.VDisplayIds[0]
+				idx := 0
 				for _, rdisplay := range vscrnDef.RealDisplays {
-					if rdisplay.VDisplayId == com.VDisplayIds[0] && rdisplay.NodeId == nodeId {
+					if rdisplay.VDisplayId != vDisplayId || rdisplay.NodeId != nodeId {
+						continue
+					}
+					if idx == assigned[vDisplayId] {
 						compositor := rvgpuCompositor{
 							rId:        rdisplay.RDisplayId,
 							conn:       nil,
 							domainName: UHMI_RVGPU_LAYOUT_SOCK + "." + com.SockDomainName,
+							connMutex:  new(sync.Mutex),
 						}
-						rvgpuComs = append(rvgpuComs, compositor)
+						compositors = append(compositors, compositor)
+						break
 					}
+					idx++
 				}
+				assigned[vDisplayId]++
 			}
 		}
 	}
+	return compositors
+}
+
+/* returns whether the node uses rvgpu compositors, without registering them */
+func HasRvgpuCompositor(
+	vscrnDef *ula.VScrnDef,
+	nodeId int) bool {
+
+	return len(newRvgpuCompositors(vscrnDef, nodeId)) > 0
+}
+
+func IsRvgpuCompositor(
+	vscrnDef *ula.VScrnDef,
+	nodeId int) bool {
+
+	rvgpuComs = append(rvgpuComs, newRvgpuCompositors(vscrnDef, nodeId)...)
 	if len(rvgpuComs) > 0 {
 		DLog.Println(rvgpuComs)
 		return true
@@ -71,88 +110,106 @@ func IsRvgpuCompositor(
 	return false
 }
 
-func retryConnectTarget(sockChan chan net.Conn, stopChan chan struct{}, domainName string) {
+/* retries the dial until the compositor timeout */
+func connectTarget(domainName string) net.Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), ulanode.GetCompositorTimeout())
+	defer cancel()
 
 	abstract_domain_sock := "@" + domainName
+	var dialer net.Dialer
 	for {
-		conn, err := net.Dial("unix", abstract_domain_sock)
+		conn, err := dialer.DialContext(ctx, "unix", abstract_domain_sock)
 		if err == nil {
-			sockChan <- conn
-			break
+			ILog.Println("Dial connected to rvgpu-compositor")
+			DLog.Printf("connect OK\n")
+			return conn
 		}
-		time.Sleep(10 * time.Millisecond)
 
 		select {
-		case <-stopChan:
-			return
-		default:
+		case <-ctx.Done():
+			ILog.Println("Dial cannot connect rvgpu-compositor: ", domainName)
+			return nil
+		case <-time.After(10 * time.Millisecond):
 		}
 	}
 }
 
-func connectTarget(domainName string) net.Conn {
-	var conn net.Conn
-
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func handleConnectTarget(compositor *rvgpuCompositor, wg *sync.WaitGroup) {
 
-	sockChan := make(chan net.Conn, 1)
-	stopChan := make(chan struct{})
+	conn := connectTarget(compositor.domainName)
+	if conn == nil {
+		wg.Done()
+		return
+	}
+	serveConnection(compositor, conn, wg)
+}
 
-	defer func() {
-		close(sockChan)
-		close(stopChan)
-	}()
+func (compositor *rvgpuCompositor) setConn(conn net.Conn, sendChan chan string, waitChan chan bool) {
+	compositor.connMutex.Lock()
+	defer compositor.connMutex.Unlock()
 
-	go retryConnectTarget(sockChan, stopChan, domainName)
+	compositor.conn = conn
+	compositor.sendChan = sendChan
+	compositor.waitChan = waitChan
+}
 
-	select {
-	case <-ctx.Done():
-		ILog.Println("Dial cannot connect rvgpu-compositor: ", domainName)
-		return nil
-	case conn = <-sockChan:
-		ILog.Println("Dial connected to rvgpu-compositor")
-	}
+func (compositor *rvgpuCompositor) isConnected() bool {
+	compositor.connMutex.Lock()
+	defer compositor.connMutex.Unlock()
 
-	DLog.Printf("connect OK\n")
-	return conn
+	return compositor.conn != nil
 }
 
-func handleConnectTarget(compositor *rvgpuCompositor, wg *sync.WaitGroup) {
+/*
+ * Queues msg to the connection, and returns the channel which gets whether the
+ * compositor completed the layout. It is closed if the connection is lost.
+ */
+func (compositor *rvgpuCompositor) send(msg string) (chan bool, bool) {
+	compositor.connMutex.Lock()
+	defer compositor.connMutex.Unlock()
 
-	compositor.conn = connectTarget(compositor.domainName)
 	if compositor.conn == nil {
-		wg.Done()
-		return
+		return nil, false
+	}
+	select {
+	case compositor.sendChan <- msg:
+		return compositor.waitChan, true
+	default:
+		return nil, false
 	}
-	compositor.waitChan = make(chan bool, 1)
-	compositor.sendChan = make(chan string, 1)
-	compositor.recvChan = make(chan []byte, 1)
+}
+
+/* runs until the connection is closed */
+func serveConnection(compositor *rvgpuCompositor, conn net.Conn, wg *sync.WaitGroup) {
+
+	sendChan := make(chan string, 1)
+	waitChan := make(chan bool, 1)
+	recvChan := make(chan []byte, 1)
+	compositor.setConn(conn, sendChan, waitChan)
 
 	defer func() {
-		compositor.conn.Close()
-		compositor.conn = nil
-		close(compositor.waitChan)
-		close(compositor.sendChan)
-		close(compositor.recvChan)
+		DLog.Println("Connection closed: ", compositor.domainName)
+		compositor.setConn(nil, nil, nil)
+		conn.Close()
+		close(waitChan)
+		close(sendChan)
+		close(recvChan)
 	}()
 
-	go connReadLoop(compositor.conn, compositor.recvChan)
+	go connReadLoop(conn, recvChan)
 	wg.Done()
 
 	for {
 		select {
-		case command := <-compositor.sendChan:
-			remoteAddr := compositor.conn.RemoteAddr()
-			DLog.Println("sendCommand:", remoteAddr)
-			sendCommand(compositor.conn, command)
-
-		case recvMsg := <-compositor.recvChan:
-			if recvMsg != nil {
-				compositor.waitChan <- true
-			} else {
+		case command := <-sendChan:
+			DLog.Println("sendCommand:", conn.RemoteAddr())
+			sendCommand(conn, command)
+
+		case recvMsg := <-recvChan:
+			if recvMsg == nil {
 				return
 			}
+			waitChan <- string(recvMsg) == LAYOUT_COMPLETE
 		}
 	}
 }
@@ -160,8 +217,8 @@ func handleConnectTarget(compositor *rvgpuCompositor, wg *sync.WaitGroup) {
 func rvgpuMultiConn(compositor *[]rvgpuCompositor) {
 
 	var wg sync.WaitGroup
-	for i, comp := range *compositor {
-		if comp.conn == nil {
+	for i := range *compositor {
+		if !(*compositor)[i].isConnected() {
 			wg.Add(1)
 			go handleConnectTarget(&(*compositor)[i], &wg)
 		}
@@ -185,50 +242,19 @@ func connectTargetOnce(domainName string) net.Conn {
 
 func handleConnectTargetOnce(compositor *rvgpuCompositor, wg *sync.WaitGroup) {
 
-	compositor.conn = connectTargetOnce(compositor.domainName)
-	if compositor.conn == nil {
+	conn := connectTargetOnce(compositor.domainName)
+	if conn == nil {
 		wg.Done()
 		return
 	}
-
-	compositor.waitChan = make(chan bool, 1)
-	compositor.sendChan = make(chan string, 1)
-	compositor.recvChan = make(chan []byte, 1)
-
-	defer func() {
-		DLog.Println("[2]defunc call")
-		compositor.conn.Close()
-		compositor.conn = nil
-		close(compositor.waitChan)
-		close(compositor.sendChan)
-		close(compositor.recvChan)
-	}()
-
-	go connReadLoop(compositor.conn, compositor.recvChan)
-	wg.Done()
-
-	for {
-		select {
-		case command := <-compositor.sendChan:
-			remoteAddr := compositor.conn.RemoteAddr()
-			DLog.Println("sendCommand:", remoteAddr)
-			sendCommand(compositor.conn, command)
-
-		case recvMsg := <-compositor.recvChan:
-			if recvMsg != nil {
-				compositor.waitChan <- true
-			} else {
-				return
-			}
-		}
-	}
+	serveConnection(compositor, conn, wg)
 }
 
 func rvgpuMultiConnOnce(compositor *[]rvgpuCompositor) {
 
 	var wg sync.WaitGroup
-	for i, comp := range *compositor {
-		if comp.conn == nil {
+	for i := range *compositor {
+		if !(*compositor)[i].isConnected() {
 			wg.Add(1)
 			go handleConnectTargetOnce(&(*compositor)[i], &wg)
 		}
@@ -240,59 +266,163 @@ func (plugin RvgpuPlugin) Start(reqChan chan ulanode.LocalCommandReq, respChan c
 
 	rvgpuMultiConn(&rvgpuComs)
 
+	connected := make([]bool, len(rvgpuComs))
+	plugin.updateConnections(&rvgpuComs, connected)
+
+	backoffs := make([]ula.Backoff, len(rvgpuComs))
+	for i := range backoffs {
+		backoffs[i] = ulanode.NewReconnectBackoff()
+	}
+	ticker := time.NewTicker(ulanode.RECONNECT_CHECK_INTERVAL)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case lComReq := <-reqChan:
 			rvgpuMultiConnOnce(&rvgpuComs)
+			plugin.updateConnections(&rvgpuComs, connected)
 			ret := sendRvgpuCompositorJson(&rvgpuComs, lComReq)
 			lcr := ulanode.LocalCommandReq{}
 			lcr.Ret = ret
 			respChan <- lcr
 			break
+
+		case <-ticker.C:
+			plugin.updateConnections(&rvgpuComs, connected)
+
+			/* the compositors are reconnected without waiting for the next request */
+			var wg sync.WaitGroup
+			for i := range rvgpuComs {
+				if !rvgpuComs[i].isConnected() && backoffs[i].IsDue() {
+					wg.Add(1)
+					go handleConnectTargetOnce(&rvgpuComs[i], &wg)
+				}
+			}
+			wg.Wait()
+			for i := range rvgpuComs {
+				if rvgpuComs[i].isConnected() {
+					backoffs[i].Reset()
+				} else if backoffs[i].IsDue() {
+					backoffs[i].Failed()
+				}
+			}
+
+			plugin.updateConnections(&rvgpuComs, connected)
 		}
 	}
 }
 
+/*
+ * Tracks the connection to each rvgpu-compositor. A restarted compositor
+ * comes back empty, so the whole applied layout of its real display is
+ * replayed when it is connected again.
+ */
+func (plugin RvgpuPlugin) updateConnections(compositor *[]rvgpuCompositor, connected []bool) {
+
+	for i := range *compositor {
+		comp := &(*compositor)[i]
+		isConnected := comp.isConnected()
+		if connected[i] && !isConnected {
+			WLog.Println("Disconnected from rvgpu-compositor: ", comp.domainName)
+			ulanode.SetCompositorConnected(comp.domainName, []int{comp.rId}, false)
+			connected[i] = false
+		} else if !connected[i] && isConnected {
+			ulanode.SetCompositorConnected(comp.domainName, []int{comp.rId}, true)
+			plugin.resync(comp)
+			connected[i] = true
+		} else if !connected[i] {
+			ulanode.SetCompositorConnected(comp.domainName, []int{comp.rId}, false)
+		}
+	}
+}
+
+func (plugin RvgpuPlugin) resync(comp *rvgpuCompositor) {
+
+	reqs, err := ulanode.GenerateResyncCommandReq(plugin, []int{comp.rId})
+	if err != nil {
+		ELog.Printf("Resync rvgpu-compositor %s error: %s", comp.domainName, err)
+		ulanode.SetCompositorResyncResult(comp.domainName, -1)
+		return
+	}
+	if len(reqs) == 0 {
+		return
+	}
+
+	/* the initial layout is sent only to this compositor, the others keep their layouts */
+	ret := 0
+	for _, req := range reqs {
+		if req.Command != "initial_vscreen" {
+			ELog.Printf("Resync rvgpu-compositor %s: unexpected request %s", comp.domainName, req.Command)
+			ret = -1
+			break
+		}
+		msg, err := nodeSentLayouts.genInitialLayoutProtocolJson(*req, comp.rId)
+		if err != nil {
+			ret = -1
+			break
+		}
+		if msg == "" {
+			continue
+		}
+		waitChan, ok := comp.send(msg)
+		if !ok {
+			ret = -1
+			break
+		}
+		if completed := <-waitChan; !completed {
+			ret = -1
+			break
+		}
+	}
+
+	if ret != 0 {
+		ELog.Printf("Resync rvgpu-compositor %s failed: %d", comp.domainName, ret)
+	} else {
+		ILog.Println("Resync rvgpu-compositor: the applied layout is replayed to ", comp.domainName)
+	}
+	ulanode.SetCompositorResyncResult(comp.domainName, ret)
+}
+
 func sendRvgpuCompositorJson(compositor *[]rvgpuCompositor, lComReq ulanode.LocalCommandReq) int {
 
 	msg := ""
 	var err error
 
 	DLog.Println("sendRvgpuCompositorJson", lComReq)
-	var rIds = make([]int, 0)
+	waitChans := make(map[int]chan bool)
 	for i := range *compositor {
 
 		comp := &(*compositor)[i]
 		switch lComReq.Command {
 		case "initial_vscreen":
-			msg, err = genInitialLayoutProtocolJson(lComReq, comp.rId)
+			msg, err = nodeSentLayouts.genInitialLayoutProtocolJson(lComReq, comp.rId)
+		case "update_layout":
+			msg, err = nodeSentLayouts.genUpdateLayoutProtocolJson(lComReq, comp.rId)
 		default:
 			WLog.Println("Error lComReq.Command")
 			continue
 		}
 
-		if msg == "" {
-			continue
-		}
 		if err != nil {
 			WLog.Println("Error ProtocolJson")
 			continue
 		}
+		if msg == "" {
+			continue
+		}
 
-		if comp.conn != nil {
-			comp.sendChan <- msg
-			rIds = append(rIds, comp.rId)
+		waitChan, ok := comp.send(msg)
+		if !ok {
+			WLog.Println("Not connected to rvgpu-compositor: ", comp.domainName)
+			continue
 		}
+		waitChans[i] = waitChan
 	}
 
-	for i := range *compositor {
-		comp := &(*compositor)[i]
-		if comp.conn != nil {
-			for _, rId := range rIds {
-				if rId == comp.rId {
-					<-comp.waitChan
-				}
-			}
+	/* a compositor which lost the connection gets the applied layout again by resync */
+	for i, waitChan := range waitChans {
+		if completed := <-waitChan; !completed {
+			WLog.Println("Layout is not completed by rvgpu-compositor: ", (*compositor)[i].domainName)
 		}
 	}
 
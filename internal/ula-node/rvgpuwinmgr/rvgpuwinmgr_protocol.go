@@ -19,6 +19,7 @@ package rvgpuwinmgr
 
 import (
 	"encoding/json"
+	"reflect"
 	"sync"
 	"ula-tools/internal/ula"
 	"ula-tools/internal/ula-node"
@@ -27,7 +28,6 @@ import (
 
 const UHMI_RVGPU_LAYOUT_SOCK string = "uhmi-rvgpu_layout_sock"
 const VERSION string = "0.0.0"
-const OPACITY float64 = 1.0
 
 type rvgpuLayoutJson struct {
 	Id             int     `json:"id"`
@@ -53,25 +53,99 @@ type safetyAreaJson struct {
 	Height int `json:"height"`
 }
 
+/*
+ * The destinations of the surfaces and safety areas are already transformed by
+ * "transform", and the sources stay in the coordinates of the surface buffers,
+ * so the compositor only transforms the content of each surface.
+ */
 type InitialLayoutProtocol struct {
 	Version      string            `json:"version"`
 	Command      string            `json:"command"`
+	Transform    string            `json:"transform"`
 	RvgpuLayouts []rvgpuLayoutJson `json:"surfaces"`
 	SafetyAreas  []safetyAreaJson  `json:"safety_areas"`
 }
 
+type removedSurfaceJson struct {
+	Id             int    `json:"id"`
+	RvgpuSurfaceID string `json:"rvgpu_surface_id"`
+}
+
+type UpdateLayoutProtocol struct {
+	Version         string               `json:"version"`
+	Command         string               `json:"command"`
+	Transform       string               `json:"transform"`
+	RvgpuLayouts    []rvgpuLayoutJson    `json:"surfaces"`
+	RemovedSurfaces []removedSurfaceJson `json:"removed_surfaces"`
+	LayerOrder      []int                `json:"layer_order,omitempty"`
+	SafetyAreas     []safetyAreaJson     `json:"safety_areas"`
+}
+
 type Key struct {
 	RDisplayId int
 	LayerID    int
 }
 
-var layerSurfacesMap = make(map[Key][]ula.PixelSurface)
-var mapMutex = sync.RWMutex{}
+/* the layouts which have been sent to the rvgpu compositors */
+type sentLayouts struct {
+	/* surface layouts of each layer */
+	layerSurfacesMap map[Key][]rvgpuLayoutJson
+
+	/* layer order (bottom to top) of each real display */
+	layerOrderMap map[int][]int
+	mapMutex      sync.RWMutex
+}
+
+func newSentLayouts() *sentLayouts {
+	return &sentLayouts{
+		layerSurfacesMap: make(map[Key][]rvgpuLayoutJson),
+		layerOrderMap:    make(map[int][]int),
+	}
+}
+
+/* the layouts sent to the compositors of this node */
+var nodeSentLayouts = newSentLayouts()
+
+func (sent *sentLayouts) addOrUpdateLayerSurfaces(rDisplayID int, layerID int, surfaces []rvgpuLayoutJson) {
+	sent.mapMutex.Lock()
+	defer sent.mapMutex.Unlock()
+	sent.layerSurfacesMap[Key{rDisplayID, layerID}] = surfaces
+}
+
+func (sent *sentLayouts) getLayerSurfaces(rDisplayID int, layerID int) ([]rvgpuLayoutJson, bool) {
+	sent.mapMutex.RLock()
+	defer sent.mapMutex.RUnlock()
+	surfaces, ok := sent.layerSurfacesMap[Key{rDisplayID, layerID}]
+	return surfaces, ok
+}
+
+func (sent *sentLayouts) removeLayerSurfaces(rDisplayID int, layerID int) {
+	sent.mapMutex.Lock()
+	defer sent.mapMutex.Unlock()
+	delete(sent.layerSurfacesMap, Key{rDisplayID, layerID})
+}
+
+func (sent *sentLayouts) setLayerOrder(rDisplayID int, layerIDs []int) {
+	sent.mapMutex.Lock()
+	defer sent.mapMutex.Unlock()
+	sent.layerOrderMap[rDisplayID] = layerIDs
+}
+
+func (sent *sentLayouts) getLayerOrder(rDisplayID int) []int {
+	sent.mapMutex.RLock()
+	defer sent.mapMutex.RUnlock()
+	return append([]int{}, sent.layerOrderMap[rDisplayID]...)
+}
 
-func addOrUpdateLayerSurfaces(rDisplayID int, layerID int, surfaces []ula.PixelSurface) {
-	mapMutex.Lock()
-	defer mapMutex.Unlock()
-	layerSurfacesMap[Key{rDisplayID, layerID}] = surfaces
+func (sent *sentLayouts) clearRdisplayLayers(rDisplayID int) {
+	sent.mapMutex.Lock()
+	defer sent.mapMutex.Unlock()
+	for key := range sent.layerSurfacesMap {
+		if key.RDisplayId == rDisplayID {
+			delete(sent.layerSurfacesMap, key)
+		}
+	}
+	delete(sent.layerOrderMap, rDisplayID)
 }
 
 func genRvgpuLayoutParams(player ula.PixelLayer, psurfaces []ula.PixelSurface) []rvgpuLayoutJson {
@@ -92,7 +166,7 @@ func genRvgpuLayoutParams(player ula.PixelLayer, psurfaces []ula.PixelSurface) [
 			DstY:           viewDstY,
 			DstW:           viewDstW,
 			DstH:           viewDstH,
-			Opacity:        OPACITY,
+			Opacity:        player.Opacity * psurf.Opacity, /* rvgpu has no layer, so both opacities are combined */
 			Visibility:     psurf.Visibility,
 		}
 		rvgpuLayouts = append(rvgpuLayouts, rvgpuLayout)
@@ -100,6 +174,29 @@ func genRvgpuLayoutParams(player ula.PixelLayer, psurfaces []ula.PixelSurface) [
 	return rvgpuLayouts
 }
 
+func genSafetyAreaParams(psafetyareas []ula.PixelSafetyArea) []safetyAreaJson {
+	var safetyareas []safetyAreaJson
+	for _, r := range psafetyareas {
+		safetyarea := safetyAreaJson{
+			X:      r.PixelX,
+			Y:      r.PixelY,
+			Width:  r.PixelW,
+			Height: r.PixelH,
+		}
+		safetyareas = append(safetyareas, safetyarea)
+	}
+	return safetyareas
+}
+
+func findRvgpuLayoutIndex(rvgpuLayouts []rvgpuLayoutJson, rvgpuSurfaceID string) int {
+	for idx, rvgpuLayout := range rvgpuLayouts {
+		if rvgpuLayout.RvgpuSurfaceID == rvgpuSurfaceID {
+			return idx
+		}
+	}
+	return -1
+}
+
 func calcSrcViewArea(player ula.PixelLayer, psurface ula.PixelSurface) (int, int, int, int, int, int, int, int) {
 	clipX, clipY, clipWidth, clipHeight := 0, 0, 0, 0
 	clipX2, clipY2 := 0, 0
@@ -182,36 +279,47 @@ func calcSrcViewArea(player ula.PixelLayer, psurface ula.PixelSurface) (int, int
 	return finalSrcX, finalSrcY, finalSrcWidth, finalSrcHeight, finalDstX, finalDstY, finalDstWidth, finalDstHeight
 }
 
-func genInitialLayoutProtocolJson(req ulanode.LocalCommandReq, rId int) (string, error) {
+/*
+ * Generates the initial_layout message for the real display rId. The message
+ * is empty if req does not initialise rId, so that the compositors of the
+ * other real displays keep their layouts.
+ */
+func (sent *sentLayouts) genInitialLayoutProtocolJson(req ulanode.LocalCommandReq, rId int) (string, error) {
 	var rvgpuLayouts []rvgpuLayoutJson
 	var safetyareas []safetyAreaJson
+	isFound := false
+	transform := ula.TRANSFORM_NORMAL
 
 	for _, rdcomm := range req.RDComms {
 
 		if rdcomm.Rdisplay.RDisplayId == rId {
 
+			isFound = true
+			transform = ula.NormalizeTransform(rdcomm.Rdisplay.Transform)
+
+			sent.clearRdisplayLayers(rId)
+			layerOrder := make([]int, 0)
 			for _, player := range rdcomm.Players {
 
 				rvgpuLayout := genRvgpuLayoutParams(player, player.Psurfaces)
-				addOrUpdateLayerSurfaces(rId, player.VID, player.Psurfaces)
+				sent.addOrUpdateLayerSurfaces(rId, player.VID, rvgpuLayout)
+				layerOrder = append(layerOrder, player.VID)
 
 				rvgpuLayouts = append(rvgpuLayouts, rvgpuLayout...)
 			}
-			for _, r := range rdcomm.SafetyAreas {
-				rvgpuLayout := safetyAreaJson{
-					X:      r.PixelX,
-					Y:      r.PixelY,
-					Width:  r.PixelW,
-					Height: r.PixelH,
-				}
-				safetyareas = append(safetyareas, rvgpuLayout)
-			}
+			sent.setLayerOrder(rId, layerOrder)
+			safetyareas = append(safetyareas, genSafetyAreaParams(rdcomm.SafetyAreas)...)
 		}
 	}
 
+	if !isFound {
+		return "", nil
+	}
+
 	rvgpuProto := InitialLayoutProtocol{
 		Version:      VERSION,
 		Command:      "initial_layout",
+		Transform:    transform,
 		RvgpuLayouts: rvgpuLayouts,
 		SafetyAreas:  safetyareas,
 	}
@@ -219,9 +327,170 @@ func genInitialLayoutProtocolJson(req ulanode.LocalCommandReq, rId int) (string,
 	jsonBytes, err := json.Marshal(rvgpuProto)
 	if err != nil {
 		ELog.Println("JSON Marshal error:", err)
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
+/*
+ * Generates the update_layout message for the real display rId.
+ * Only the surfaces which are added or changed from the ones already sent
+ * (held in sent) are listed in "surfaces", and the surfaces which
+ * disappeared are listed in "removed_surfaces". "layer_order" is set only when
+ * the stacking order of the layers is changed.
+ */
+func (sent *sentLayouts) genUpdateLayoutProtocolJson(req ulanode.LocalCommandReq, rId int) (string, error) {
+	rvgpuLayouts := make([]rvgpuLayoutJson, 0)
+	removedSurfaces := make([]removedSurfaceJson, 0)
+	safetyareas := make([]safetyAreaJson, 0)
+	var layerOrder []int
+	isFound := false
+	transform := ula.TRANSFORM_NORMAL
+
+	for _, rdcomm := range req.RDComms {
+
+		if rdcomm.Rdisplay.RDisplayId != rId {
+			continue
+		}
+		transform = ula.NormalizeTransform(rdcomm.Rdisplay.Transform)
+		isFound = true
+
+		oldLayerOrder := sent.getLayerOrder(rId)
+		newLayerOrder := make([]int, 0)
+		newLayerMap := make(map[int]bool)
+
+		for _, player := range rdcomm.Players {
+			newLayerOrder = append(newLayerOrder, player.VID)
+			newLayerMap[player.VID] = true
+
+			newLayouts := genRvgpuLayoutParams(player, player.Psurfaces)
+			oldLayouts, _ := sent.getLayerSurfaces(rId, player.VID)
+
+			for _, newLayout := range newLayouts {
+				idx := findRvgpuLayoutIndex(oldLayouts, newLayout.RvgpuSurfaceID)
+				if idx < 0 || !reflect.DeepEqual(oldLayouts[idx], newLayout) {
+					rvgpuLayouts = append(rvgpuLayouts, newLayout)
+				}
+			}
+			for _, oldLayout := range oldLayouts {
+				if findRvgpuLayoutIndex(newLayouts, oldLayout.RvgpuSurfaceID) < 0 {
+					removedSurfaces = append(removedSurfaces, removedSurfaceJson{
+						Id:             oldLayout.Id,
+						RvgpuSurfaceID: oldLayout.RvgpuSurfaceID,
+					})
+				}
+			}
+
+			sent.addOrUpdateLayerSurfaces(rId, player.VID, newLayouts)
+		}
+
+		for _, layerId := range oldLayerOrder {
+			if newLayerMap[layerId] {
+				continue
+			}
+			oldLayouts, _ := sent.getLayerSurfaces(rId, layerId)
+			for _, oldLayout := range oldLayouts {
+				removedSurfaces = append(removedSurfaces, removedSurfaceJson{
+					Id:             oldLayout.Id,
+					RvgpuSurfaceID: oldLayout.RvgpuSurfaceID,
+				})
+			}
+			sent.removeLayerSurfaces(rId, layerId)
+		}
+
+		if !reflect.DeepEqual(oldLayerOrder, newLayerOrder) {
+			layerOrder = newLayerOrder
+		}
+		sent.setLayerOrder(rId, newLayerOrder)
+
+		safetyareas = append(safetyareas, genSafetyAreaParams(rdcomm.SafetyAreas)...)
+	}
+
+	if !isFound {
+		return "", nil
+	}
+	if len(rvgpuLayouts) == 0 && len(removedSurfaces) == 0 && layerOrder == nil {
+		return "", nil
+	}
+
+	rvgpuProto := UpdateLayoutProtocol{
+		Version:         VERSION,
+		Command:         "update_layout",
+		Transform:       transform,
+		RvgpuLayouts:    rvgpuLayouts,
+		RemovedSurfaces: removedSurfaces,
+		LayerOrder:      layerOrder,
+		SafetyAreas:     safetyareas,
+	}
+
+	jsonBytes, err := json.Marshal(rvgpuProto)
+	if err != nil {
+		ELog.Println("JSON Marshal error:", err)
+		return "", err
 	}
 
-	msg := string(jsonBytes)
+	return string(jsonBytes), nil
+}
+
+/*
+ * Generates an approximation of the messages to each rvgpu compositor of the
+ * node for acdata without sending them. sps is the layout which has been
+ * applied to the node, and the diffs of update_layout are taken against it
+ * instead of the layouts which ula-node has sent.
+ */
+func GenerateDryRunCommands(vscrnDef *ula.VScrnDef, nodeId int, acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens) ([]ulanode.CompositorCommand, error) {
+	plugin := RvgpuPlugin{}
+	compositors := newRvgpuCompositors(vscrnDef, nodeId)
+
+	sent := newSentLayouts()
+	initAcdata := &ula.ApplyCommandData{
+		Command:   "initial_vscreen",
+		NPScreens: sps,
+	}
+	initReqs, err := plugin.GenerateLocalCommandReq(initAcdata, new(ula.NodePixelScreens))
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range initReqs {
+		for _, comp := range compositors {
+			_, err = sent.genInitialLayoutProtocolJson(*req, comp.rId)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	reqs, err := plugin.GenerateLocalCommandReq(acdata, sps)
+	if err != nil {
+		return nil, err
+	}
+
+	comms := make([]ulanode.CompositorCommand, 0)
+	for _, comp := range compositors {
+		comm := ulanode.CompositorCommand{
+			Compositor:  comp.domainName,
+			RDisplayIds: []int{comp.rId},
+			Messages:    make([]string, 0),
+		}
+		for _, req := range reqs {
+			msg := ""
+			switch req.Command {
+			case "initial_vscreen":
+				msg, err = sent.genInitialLayoutProtocolJson(*req, comp.rId)
+			case "update_layout":
+				msg, err = sent.genUpdateLayoutProtocolJson(*req, comp.rId)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if msg == "" {
+				continue
+			}
+			comm.Messages = append(comm.Messages, msg)
+		}
+		comms = append(comms, comm)
+	}
 
-	return msg, nil
+	return comms, nil
 }
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rvgpuwinmgr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"ula-tools/internal/ula"
+	"ula-tools/internal/ula-node"
+)
+
+func testSurface(appName string, vid int, x int) ula.PixelSurface {
+	return ula.PixelSurface{
+		AppName: appName, VID: vid, PixelW: 640, PixelH: 480,
+		PsrcW: 640, PsrcH: 480,
+		PdstX: x, PdstW: 640, PdstH: 480,
+		Visibility: 1, Opacity: 1,
+	}
+}
+
+func testLayer(vid int, psurfaces ...ula.PixelSurface) ula.PixelLayer {
+	return ula.PixelLayer{
+		VID: vid, PixelW: 1920, PixelH: 1080,
+		PsrcW: 1920, PsrcH: 1080,
+		PdstW: 1920, PdstH: 1080,
+		Visibility: 1, Opacity: 1,
+		Psurfaces: psurfaces,
+	}
+}
+
+func testScreen(rId int, players ...ula.PixelLayer) ula.PixelScreen {
+	return ula.PixelScreen{
+		Rdisplay: ula.RealDisplay{RDisplayId: rId, PixelW: 1920, PixelH: 1080},
+		Players:  players,
+	}
+}
+
+/* generates the messages of each real display as sendRvgpuCompositorJson does */
+func testGenMessages(t *testing.T, sent *sentLayouts, acdata *ula.ApplyCommandData, sps *ula.NodePixelScreens, rIds []int) map[int][]string {
+	reqs, err := RvgpuPlugin{}.GenerateLocalCommandReq(acdata, sps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := make(map[int][]string)
+	for _, req := range reqs {
+		for _, rId := range rIds {
+			msg := ""
+			switch req.Command {
+			case "initial_vscreen":
+				msg, err = sent.genInitialLayoutProtocolJson(*req, rId)
+			case "update_layout":
+				msg, err = sent.genUpdateLayoutProtocolJson(*req, rId)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != "" {
+				msgs[rId] = append(msgs[rId], msg)
+			}
+		}
+	}
+	return msgs
+}
+
+func TestInitialLayoutOnlyForInitialisedDisplay(t *testing.T) {
+	sent := newSentLayouts()
+	rIds := []int{0, 1}
+
+	sps := &ula.NodePixelScreens{Pscreens: []ula.PixelScreen{
+		testScreen(0, testLayer(100, testSurface("app0", 10, 0))),
+		testScreen(1),
+	}}
+	testGenMessages(t, sent, &ula.ApplyCommandData{Command: "initial_vscreen", NPScreens: sps}, new(ula.NodePixelScreens), rIds)
+	sentRd0, _ := sent.getLayerSurfaces(0, 100)
+
+	/* only the empty real display 1 gets its first layout */
+	acdata := &ula.ApplyCommandData{Command: "add_vlayer", NPScreens: &ula.NodePixelScreens{Pscreens: []ula.PixelScreen{
+		testScreen(0, testLayer(100, testSurface("app0", 10, 0))),
+		testScreen(1, testLayer(200, testSurface("app1", 20, 0))),
+	}}}
+	msgs := testGenMessages(t, sent, acdata, sps, rIds)
+
+	if len(msgs[0]) != 0 {
+		t.Errorf("real display 0 is not changed, but got %v", msgs[0])
+	}
+	if len(msgs[1]) != 1 {
+		t.Fatalf("real display 1 expects one initial_layout, got %v", msgs[1])
+	}
+	var proto InitialLayoutProtocol
+	if err := json.Unmarshal([]byte(msgs[1][0]), &proto); err != nil {
+		t.Fatal(err)
+	}
+	if proto.Command != "initial_layout" || len(proto.RvgpuLayouts) != 1 || proto.RvgpuLayouts[0].RvgpuSurfaceID != "app1" {
+		t.Errorf("unexpected initial_layout of real display 1: %s", msgs[1][0])
+	}
+	if layouts, _ := sent.getLayerSurfaces(0, 100); !reflect.DeepEqual(layouts, sentRd0) {
+		t.Errorf("sent layouts of real display 0 are changed: %v", layouts)
+	}
+}
+
+func TestUpdateLayoutDiff(t *testing.T) {
+	base := []ula.PixelScreen{
+		testScreen(0,
+			testLayer(100, testSurface("app0", 10, 0), testSurface("app1", 11, 640)),
+			testLayer(200, testSurface("app2", 20, 0))),
+	}
+
+	tests := []struct {
+		name        string
+		pscreens    []ula.PixelScreen
+		wantMessage bool
+		surfaces    []string
+		removed     []string
+		layerOrder  []int
+	}{
+		{
+			name:     "no change",
+			pscreens: base,
+		},
+		{
+			name: "surface moved",
+			pscreens: []ula.PixelScreen{
+				testScreen(0,
+					testLayer(100, testSurface("app0", 10, 0), testSurface("app1", 11, 320)),
+					testLayer(200, testSurface("app2", 20, 0))),
+			},
+			wantMessage: true,
+			surfaces:    []string{"app1"},
+		},
+		{
+			name: "surface removed",
+			pscreens: []ula.PixelScreen{
+				testScreen(0,
+					testLayer(100, testSurface("app0", 10, 0)),
+					testLayer(200, testSurface("app2", 20, 0))),
+			},
+			wantMessage: true,
+			removed:     []string{"app1"},
+		},
+		{
+			name: "layer removed",
+			pscreens: []ula.PixelScreen{
+				testScreen(0,
+					testLayer(100, testSurface("app0", 10, 0), testSurface("app1", 11, 640))),
+			},
+			wantMessage: true,
+			removed:     []string{"app2"},
+			layerOrder:  []int{100},
+		},
+		{
+			name: "layers restacked",
+			pscreens: []ula.PixelScreen{
+				testScreen(0,
+					testLayer(200, testSurface("app2", 20, 0)),
+					testLayer(100, testSurface("app0", 10, 0), testSurface("app1", 11, 640))),
+			},
+			wantMessage: true,
+			layerOrder:  []int{200, 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := newSentLayouts()
+			sps := &ula.NodePixelScreens{Pscreens: base}
+			testGenMessages(t, sent, &ula.ApplyCommandData{Command: "initial_vscreen", NPScreens: sps}, new(ula.NodePixelScreens), []int{0})
+
+			acdata := &ula.ApplyCommandData{Command: "set_vlayer", NPScreens: &ula.NodePixelScreens{Pscreens: tt.pscreens}}
+			msgs := testGenMessages(t, sent, acdata, sps, []int{0})
+			if !tt.wantMessage {
+				if len(msgs[0]) != 0 {
+					t.Fatalf("expects no message, got %v", msgs[0])
+				}
+				return
+			}
+			if len(msgs[0]) != 1 {
+				t.Fatalf("expects one update_layout, got %v", msgs[0])
+			}
+
+			var proto UpdateLayoutProtocol
+			if err := json.Unmarshal([]byte(msgs[0][0]), &proto); err != nil {
+				t.Fatal(err)
+			}
+			if proto.Command != "update_layout" {
+				t.Errorf("command is %s", proto.Command)
+			}
+			surfaces := make([]string, 0)
+			for _, layout := range proto.RvgpuLayouts {
+				surfaces = append(surfaces, layout.RvgpuSurfaceID)
+			}
+			removed := make([]string, 0)
+			for _, surface := range proto.RemovedSurfaces {
+				removed = append(removed, surface.RvgpuSurfaceID)
+			}
+			if tt.surfaces == nil {
+				tt.surfaces = []string{}
+			}
+			if tt.removed == nil {
+				tt.removed = []string{}
+			}
+			if !reflect.DeepEqual(surfaces, tt.surfaces) {
+				t.Errorf("surfaces = %v, want %v", surfaces, tt.surfaces)
+			}
+			if !reflect.DeepEqual(removed, tt.removed) {
+				t.Errorf("removed_surfaces = %v, want %v", removed, tt.removed)
+			}
+			if !reflect.DeepEqual(proto.LayerOrder, tt.layerOrder) {
+				t.Errorf("layer_order = %v, want %v", proto.LayerOrder, tt.layerOrder)
+			}
+		})
+	}
+}
+
+/* the messages of a request which does not have the real display */
+func TestProtocolJsonOfOtherDisplay(t *testing.T) {
+	sent := newSentLayouts()
+	req := ulanode.LocalCommandReq{Command: "initial_vscreen"}
+	for _, gen := range []func(ulanode.LocalCommandReq, int) (string, error){
+		sent.genInitialLayoutProtocolJson,
+		sent.genUpdateLayoutProtocolJson,
+	} {
+		msg, err := gen(req, 0)
+		if err != nil || msg != "" {
+			t.Errorf("expects no message, got %q %v", msg, err)
+		}
+	}
+}
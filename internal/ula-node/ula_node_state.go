@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ulanode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"ula-tools/internal/ula"
+)
+
+/*
+ * Saves the ApplyCommandData which was applied last to the state file.
+ * It is written to a temporary file and renamed, so that the state file is
+ * never left half-written.
+ */
+func SaveNodeState(stateFile string, acdata *ula.ApplyCommandData) error {
+	state := ula.ApplyCommandData{
+		Command:    acdata.Command,
+		ChgIds:     acdata.ChgIds,
+		NPScreens:  acdata.NPScreens,
+		Generation: acdata.Generation,
+	}
+
+	jsonBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(stateFile), filepath.Base(stateFile)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(jsonBytes)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if cerr := tmpFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), stateFile)
+}
+
+/* loads the ApplyCommandData saved by SaveNodeState */
+func LoadNodeState(stateFile string, nodeId int) (*ula.ApplyCommandData, error) {
+	jsonBytes, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	acdata := new(ula.ApplyCommandData)
+	err = json.Unmarshal(jsonBytes, acdata)
+	if err != nil {
+		return nil, err
+	}
+
+	if acdata.NPScreens == nil {
+		return nil, errors.New("state file has no NPScreens")
+	}
+	if acdata.NPScreens.NodeId != nodeId {
+		return nil, errors.New(fmt.Sprintf("state file is for node %d, not for node %d", acdata.NPScreens.NodeId, nodeId))
+	}
+
+	return acdata, nil
+}
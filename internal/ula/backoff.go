@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import (
+	"time"
+)
+
+/*
+ * Interval of the reconnection attempts, doubled on each failure from Min up
+ * to Max. DEFAULT_RETRY_BACKOFF and MAX_RETRY_BACKOFF are used for the
+ * zero values.
+ */
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+
+	next     time.Time
+	interval time.Duration
+}
+
+func (backoff *Backoff) IsDue() bool {
+	return !time.Now().Before(backoff.next)
+}
+
+func (backoff *Backoff) Failed() {
+	min, max := backoff.Min, backoff.Max
+	if min <= 0 {
+		min = DEFAULT_RETRY_BACKOFF
+	}
+	if max <= 0 {
+		max = MAX_RETRY_BACKOFF
+	}
+
+	if backoff.interval == 0 {
+		backoff.interval = min
+	} else if backoff.interval < max {
+		backoff.interval *= 2
+	}
+	if backoff.interval > max {
+		backoff.interval = max
+	}
+	backoff.next = time.Now().Add(backoff.interval)
+}
+
+/* the interval set by the last Failed */
+func (backoff *Backoff) Interval() time.Duration {
+	return backoff.interval
+}
+
+func (backoff *Backoff) Reset() {
+	backoff.interval = 0
+	backoff.next = time.Time{}
+}
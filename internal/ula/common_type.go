@@ -55,6 +55,7 @@ type VirtualSurface struct {
 	VdstH int
 
 	Visibility int
+	Opacity    float64
 }
 
 type VirtualLayer struct {
@@ -79,6 +80,7 @@ type VirtualLayer struct {
 	VdstH int
 
 	Visibility int
+	Opacity    float64
 
 	Vsurfaces []VirtualSurface
 }
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import "strings"
+
+/*
+ * Transform of a real display, named as in weston.ini.
+ * The rotations are clockwise, so "90" shows the top of the virtual display
+ * on the right edge of the panel. The flipped transforms mirror the image
+ * horizontally before rotating it.
+ */
+const (
+	TRANSFORM_NORMAL      = "normal"
+	TRANSFORM_90          = "90"
+	TRANSFORM_180         = "180"
+	TRANSFORM_270         = "270"
+	TRANSFORM_FLIPPED     = "flipped"
+	TRANSFORM_FLIPPED_90  = "flipped-90"
+	TRANSFORM_FLIPPED_180 = "flipped-180"
+	TRANSFORM_FLIPPED_270 = "flipped-270"
+)
+
+var transforms = []string{
+	TRANSFORM_NORMAL, TRANSFORM_90, TRANSFORM_180, TRANSFORM_270,
+	TRANSFORM_FLIPPED, TRANSFORM_FLIPPED_90, TRANSFORM_FLIPPED_180, TRANSFORM_FLIPPED_270,
+}
+
+/* returns the transform, or "normal" if it is not specified */
+func NormalizeTransform(transform string) string {
+	if transform == "" {
+		return TRANSFORM_NORMAL
+	}
+	return transform
+}
+
+func IsValidTransform(transform string) bool {
+	transform = NormalizeTransform(transform)
+	for _, t := range transforms {
+		if t == transform {
+			return true
+		}
+	}
+	return false
+}
+
+func GetTransforms() []string {
+	return append([]string{}, transforms...)
+}
+
+func transformRotation(transform string) (string, bool) {
+	transform = NormalizeTransform(transform)
+	if transform == TRANSFORM_FLIPPED {
+		return TRANSFORM_NORMAL, true
+	}
+	if strings.HasPrefix(transform, TRANSFORM_FLIPPED+"-") {
+		return strings.TrimPrefix(transform, TRANSFORM_FLIPPED+"-"), true
+	}
+	return transform, false
+}
+
+/* returns whether the transform swaps the width and the height */
+func IsTransformSwapped(transform string) bool {
+	rotation, _ := transformRotation(transform)
+	return rotation == TRANSFORM_90 || rotation == TRANSFORM_270
+}
+
+/* returns the size of the area w x h after the transform */
+func TransformSize(transform string, w int, h int) (int, int) {
+	if IsTransformSwapped(transform) {
+		return h, w
+	}
+	return w, h
+}
+
+/*
+ * Transforms the rectangle (x, y, w, h) in the area areaW x areaH, and
+ * returns the rectangle in the transformed area (see TransformSize).
+ */
+func TransformRect(transform string, areaW int, areaH int, x int, y int, w int, h int) (int, int, int, int) {
+	rotation, flipped := transformRotation(transform)
+	if flipped {
+		x = areaW - x - w
+	}
+
+	switch rotation {
+	case TRANSFORM_90:
+		return areaH - y - h, x, h, w
+	case TRANSFORM_180:
+		return areaW - x - w, areaH - y - h, w, h
+	case TRANSFORM_270:
+		return y, areaW - x - w, h, w
+	}
+	return x, y, w, h
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import (
+	"testing"
+)
+
+func TestNormalizeTransform(t *testing.T) {
+	tests := []struct {
+		transform string
+		want      string
+		valid     bool
+	}{
+		{"", TRANSFORM_NORMAL, true},
+		{TRANSFORM_NORMAL, TRANSFORM_NORMAL, true},
+		{TRANSFORM_90, TRANSFORM_90, true},
+		{TRANSFORM_FLIPPED_270, TRANSFORM_FLIPPED_270, true},
+		{"45", "45", false},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTransform(tt.transform); got != tt.want {
+			t.Errorf("NormalizeTransform(%q) = %q, want %q", tt.transform, got, tt.want)
+		}
+		if got := IsValidTransform(tt.transform); got != tt.valid {
+			t.Errorf("IsValidTransform(%q) = %v, want %v", tt.transform, got, tt.valid)
+		}
+	}
+}
+
+type testRect struct {
+	x, y, w, h int
+}
+
+func TestTransformRect(t *testing.T) {
+	/* the rectangle (100, 200, 300, 400) in the area 1920x1080 */
+	tests := []struct {
+		transform string
+		swapped   bool
+		want      testRect
+	}{
+		{TRANSFORM_NORMAL, false, testRect{100, 200, 300, 400}},
+		{TRANSFORM_90, true, testRect{480, 100, 400, 300}},
+		{TRANSFORM_180, false, testRect{1520, 480, 300, 400}},
+		{TRANSFORM_270, true, testRect{200, 1520, 400, 300}},
+		{TRANSFORM_FLIPPED, false, testRect{1520, 200, 300, 400}},
+		{TRANSFORM_FLIPPED_90, true, testRect{480, 1520, 400, 300}},
+		{TRANSFORM_FLIPPED_180, false, testRect{100, 480, 300, 400}},
+		{TRANSFORM_FLIPPED_270, true, testRect{200, 100, 400, 300}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transform, func(t *testing.T) {
+			var got testRect
+			got.x, got.y, got.w, got.h = TransformRect(tt.transform, 1920, 1080, 100, 200, 300, 400)
+			if got != tt.want {
+				t.Errorf("TransformRect = %v, want %v", got, tt.want)
+			}
+			if swapped := IsTransformSwapped(tt.transform); swapped != tt.swapped {
+				t.Errorf("IsTransformSwapped = %v, want %v", swapped, tt.swapped)
+			}
+			w, h := TransformSize(tt.transform, 1920, 1080)
+			if tt.swapped && (w != 1080 || h != 1920) || !tt.swapped && (w != 1920 || h != 1080) {
+				t.Errorf("TransformSize = %dx%d", w, h)
+			}
+		})
+	}
+}
+
+/* transforming back by the inverse transform in the transformed area gives the original rectangle */
+func TestTransformRectRoundTrip(t *testing.T) {
+	tests := []struct {
+		transform string
+		inverse   string
+	}{
+		{TRANSFORM_NORMAL, TRANSFORM_NORMAL},
+		{TRANSFORM_90, TRANSFORM_270},
+		{TRANSFORM_180, TRANSFORM_180},
+		{TRANSFORM_270, TRANSFORM_90},
+		{TRANSFORM_FLIPPED, TRANSFORM_FLIPPED},
+		{TRANSFORM_FLIPPED_90, TRANSFORM_FLIPPED_90},
+		{TRANSFORM_FLIPPED_180, TRANSFORM_FLIPPED_180},
+		{TRANSFORM_FLIPPED_270, TRANSFORM_FLIPPED_270},
+	}
+	rects := []testRect{
+		{0, 0, 1920, 1080},
+		{100, 200, 300, 400},
+		{1600, 0, 320, 240},
+	}
+
+	for _, tt := range tests {
+		for _, r := range rects {
+			areaW, areaH := TransformSize(tt.transform, 1920, 1080)
+			x, y, w, h := TransformRect(tt.transform, 1920, 1080, r.x, r.y, r.w, r.h)
+			var got testRect
+			got.x, got.y, got.w, got.h = TransformRect(tt.inverse, areaW, areaH, x, y, w, h)
+			if got != r {
+				t.Errorf("%s then %s: %v -> %v", tt.transform, tt.inverse, r, got)
+			}
+		}
+	}
+}
@@ -56,3 +56,16 @@ func GetEnvBool(key string, fallback bool) bool {
 
 	return fallback
 }
+
+func GetEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	valueInt, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return valueInt
+}
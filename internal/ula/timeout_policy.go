@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import (
+	"time"
+)
+
+const (
+	DEFAULT_COMMAND_TIMEOUT    = 1 * time.Second
+	DEFAULT_CONNECT_TIMEOUT    = 1 * time.Second
+	DEFAULT_RETRY_COUNT        = 3
+	DEFAULT_RETRY_BACKOFF      = 100 * time.Millisecond
+	DEFAULT_COMPOSITOR_TIMEOUT = 1 * time.Second
+	MAX_RETRY_BACKOFF          = 30 * time.Second
+)
+
+/*
+ * CommandTimeout is the time to wait for the response of a command from ula-node,
+ * ConnectTimeout is the time of each dial to ula-node, and RetryCount is the number
+ * of the dials after the first one when the connection is lost, waiting RetryBackoff
+ * doubled each time between them. CompositorTimeout is the time for ula-node to
+ * connect to its compositor.
+ */
+type TimeoutPolicy struct {
+	CommandTimeout    time.Duration
+	ConnectTimeout    time.Duration
+	RetryCount        int
+	RetryBackoff      time.Duration
+	CompositorTimeout time.Duration
+}
+
+func msToDuration(ms int, fallback time.Duration) time.Duration {
+	if ms <= 0 {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+/*
+ * The policy is read from the "ula" section of the framework_node, and the
+ * environment variables override it for all nodes.
+ */
+func (vdef *VScrnDef) GetTimeoutPolicy(nodeId int) TimeoutPolicy {
+	policy := TimeoutPolicy{
+		CommandTimeout:    DEFAULT_COMMAND_TIMEOUT,
+		ConnectTimeout:    DEFAULT_CONNECT_TIMEOUT,
+		RetryCount:        DEFAULT_RETRY_COUNT,
+		RetryBackoff:      DEFAULT_RETRY_BACKOFF,
+		CompositorTimeout: DEFAULT_COMPOSITOR_TIMEOUT,
+	}
+
+	for _, r := range vdef.DistributedWindowSystem.FrameworkNode {
+		if nodeId != r.NodeId {
+			continue
+		}
+		policy.CommandTimeout = msToDuration(r.Ula.CommandTimeoutMs, policy.CommandTimeout)
+		policy.ConnectTimeout = msToDuration(r.Ula.ConnectTimeoutMs, policy.ConnectTimeout)
+		if r.Ula.RetryCount != nil && *r.Ula.RetryCount >= 0 {
+			policy.RetryCount = *r.Ula.RetryCount
+		}
+		policy.RetryBackoff = msToDuration(r.Ula.RetryBackoffMs, policy.RetryBackoff)
+		policy.CompositorTimeout = msToDuration(r.Ula.CompositorTimeoutMs, policy.CompositorTimeout)
+	}
+
+	policy.CommandTimeout = msToDuration(GetEnvInt("ULA_COMMAND_TIMEOUT_MS", 0), policy.CommandTimeout)
+	policy.ConnectTimeout = msToDuration(GetEnvInt("ULA_CONNECT_TIMEOUT_MS", 0), policy.ConnectTimeout)
+	if retryCount := GetEnvInt("ULA_RETRY_COUNT", -1); retryCount >= 0 {
+		policy.RetryCount = retryCount
+	}
+	policy.RetryBackoff = msToDuration(GetEnvInt("ULA_RETRY_BACKOFF_MS", 0), policy.RetryBackoff)
+	policy.CompositorTimeout = msToDuration(GetEnvInt("ULA_COMPOSITOR_TIMEOUT_MS", 0), policy.CompositorTimeout)
+
+	return policy
+}
+
+/* all retries and reconnections of the node wait by this backoff */
+func (policy TimeoutPolicy) NewBackoff() Backoff {
+	return Backoff{Min: policy.RetryBackoff, Max: MAX_RETRY_BACKOFF}
+}
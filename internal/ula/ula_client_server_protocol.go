@@ -23,10 +23,51 @@ type IdPair struct {
 	SurfaceId int `json:"SurfaceId"`
 }
 
+/*
+ * A layout command is applied to all nodes in two phases.
+ * "prepare" validates the command and keeps it pending, "commit" applies the
+ * pending command to the compositor, and "abort" drops the pending command or
+ * rolls back the committed one. The command without Phase is applied at once.
+ */
+const (
+	PHASE_PREPARE = "prepare"
+	PHASE_COMMIT  = "commit"
+	PHASE_ABORT   = "abort"
+)
+
 type ApplyCommandData struct {
-	Command   string            `json:"Command"`
-	ChgIds    []IdPair          `json:"ChgIds"`
-	NPScreens *NodePixelScreens `json:"NPScreens"`
+	Command    string            `json:"Command"`
+	ChgIds     []IdPair          `json:"ChgIds"`
+	NPScreens  *NodePixelScreens `json:"NPScreens"`
+	Phase      string            `json:"Phase,omitempty"`
+	TxId       uint64            `json:"TxId,omitempty"`
+	Generation uint64            `json:"Generation,omitempty"`
+}
+
+/*
+ * The request {"type": "status"} is answered with {"type": "status", "result": 0,
+ * "status": NodeStatus}, and {"type": "heartbeat"} with {"type": "heartbeat",
+ * "result": 0}, instead of applying a layout command.
+ */
+const (
+	REQUEST_TYPE_STATUS    = "status"
+	REQUEST_TYPE_HEARTBEAT = "heartbeat"
+)
+
+type NodeStatus struct {
+	NodeId     int              `json:"NodeId"`
+	PluginType string           `json:"PluginType"`
+	Generation uint64           `json:"Generation"`
+	UptimeSec  int64            `json:"UptimeSec"`
+	RDisplays  []RDisplayStatus `json:"RDisplays"`
+}
+
+/* connection state of the compositor which shows the real display */
+type RDisplayStatus struct {
+	RDisplayId       int  `json:"RDisplayId"`
+	Connected        bool `json:"Connected"`
+	Reconnects       int  `json:"Reconnects"`
+	LastResyncResult int  `json:"LastResyncResult"`
 }
 
 type NodePixelScreens struct {
@@ -41,11 +82,12 @@ type PixelScreen struct {
 }
 
 type RealDisplay struct {
-	NodeId     int `json:"NodeId"`
-	PixelW     int `json:"PixelW"`
-	PixelH     int `json:"PixelH"`
-	VDisplayId int `json:"VDisplayId"`
-	RDisplayId int `json:"RDisplayId"`
+	NodeId     int    `json:"NodeId"`
+	PixelW     int    `json:"PixelW"`
+	PixelH     int    `json:"PixelH"`
+	VDisplayId int    `json:"VDisplayId"`
+	RDisplayId int    `json:"RDisplayId"`
+	Transform  string `json:"Transform,omitempty"`
 }
 
 type PixelLayer struct {
@@ -65,7 +107,8 @@ type PixelLayer struct {
 	PdstW int `json:"PdstW"`
 	PdstH int `json:"PdstH"`
 
-	Visibility int `json:"Visibility"`
+	Visibility int     `json:"Visibility"`
+	Opacity    float64 `json:"Opacity"`
 
 	Psurfaces []PixelSurface `json:"Psurfaces"`
 }
@@ -89,7 +132,8 @@ type PixelSurface struct {
 	PdstW int `json:"PdstW"`
 	PdstH int `json:"PdstH"`
 
-	Visibility int `json:"Visibility"`
+	Visibility int     `json:"Visibility"`
+	Opacity    float64 `json:"Opacity"`
 }
 
 type PixelSafetyArea struct {
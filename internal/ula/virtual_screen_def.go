@@ -43,11 +43,12 @@ type VScrnDef struct {
 	} `json:"virtual_screen_2d"`
 
 	RealDisplays []struct {
-		NodeId     int `json:"node_id"`
-		VDisplayId int `json:"vdisplay_id"`
-		PixelW     int `json:"pixel_w"`
-		PixelH     int `json:"pixel_h"`
-		RDisplayId int `json:"rdisplay_id"`
+		NodeId     int    `json:"node_id"`
+		VDisplayId int    `json:"vdisplay_id"`
+		PixelW     int    `json:"pixel_w"`
+		PixelH     int    `json:"pixel_h"`
+		RDisplayId int    `json:"rdisplay_id"`
+		Transform  string `json:"transform"`
 	} `json:"real_displays"`
 
 	Nodes []struct {
@@ -64,9 +65,17 @@ type VScrnDef struct {
 		FrameworkNode []struct {
 			NodeId int `json:"node_id"`
 			Ula    struct {
-				Debug     bool `json:"debug"`
-				DebugPort int  `json:"debug_port"`
-				Port      int  `json:"port"`
+				Debug         bool   `json:"debug"`
+				DebugPort     int    `json:"debug_port"`
+				Port          int    `json:"port"`
+				StateFile     string `json:"state_file"`
+				RestoreLayout bool   `json:"restore_layout"`
+
+				CommandTimeoutMs    int  `json:"command_timeout_ms"`
+				ConnectTimeoutMs    int  `json:"connect_timeout_ms"`
+				RetryCount          *int `json:"retry_count"`
+				RetryBackoffMs      int  `json:"retry_backoff_ms"`
+				CompositorTimeoutMs int  `json:"compositor_timeout_ms"`
 			} `json:"ula"`
 			Compositor []struct {
 				VDisplayIds    []int  `json:"vdisplay_ids"`
@@ -83,7 +92,7 @@ type VScrnDef struct {
 	} `json:"virtual_safety_area"`
 }
 
-func ReadVScrnDef(vsdPath ...string) (*VScrnDef, error) {
+func readVScrnDefFile(vsdPath ...string) (string, []byte, error) {
 	var fname string
 	if len(vsdPath) > 0 && vsdPath[0] != "" {
 		fname = vsdPath[0]
@@ -92,23 +101,38 @@ func ReadVScrnDef(vsdPath ...string) (*VScrnDef, error) {
 	}
 	f, err := os.Open(fname)
 	if err != nil {
-		return nil, err
+		return fname, nil, err
 	}
+	defer f.Close()
 
 	jsonBytes, err := ioutil.ReadAll(f)
 	if err != nil {
-		return nil, err
+		return fname, nil, err
 	}
 
+	return fname, jsonBytes, nil
+}
+
+/* the decode error is returned as VScrnDefError with the line where it occurred */
+func decodeVScrnDef(fname string, jsonBytes []byte) (*VScrnDef, error) {
 	var vscrnDef VScrnDef
-	err = json.Unmarshal(jsonBytes, &vscrnDef)
+	err := json.Unmarshal(jsonBytes, &vscrnDef)
 	if err != nil {
-		return nil, errors.New("json decode error")
+		return nil, newDecodeError(fname, jsonBytes, err)
 	}
 
 	return &vscrnDef, nil
 }
 
+func ReadVScrnDef(vsdPath ...string) (*VScrnDef, error) {
+	fname, jsonBytes, err := readVScrnDefFile(vsdPath...)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeVScrnDef(fname, jsonBytes)
+}
+
 func (vdef *VScrnDef) IsVDisplayInNode(nodeId int, vDisplayId int) bool {
 
 	for _, rdisplay := range vdef.RealDisplays {
@@ -159,6 +183,30 @@ func (vdef *VScrnDef) GetPort(nodeId int) (int, error) {
 	return -1, errors.New("Cannot Find My Port from VScrnDef json")
 }
 
+/* returns whether the debug endpoint of ula-node is enabled and its port */
+func (vdef *VScrnDef) GetDebugPort(nodeId int) (bool, int) {
+
+	for _, r := range vdef.DistributedWindowSystem.FrameworkNode {
+		if nodeId == r.NodeId {
+			return r.Ula.Debug, r.Ula.DebugPort
+		}
+	}
+
+	return false, 0
+}
+
+/* returns the state file path and whether the layout is restored on startup */
+func (vdef *VScrnDef) GetStateFile(nodeId int) (string, bool) {
+
+	for _, r := range vdef.DistributedWindowSystem.FrameworkNode {
+		if nodeId == r.NodeId {
+			return r.Ula.StateFile, r.Ula.RestoreLayout
+		}
+	}
+
+	return "", false
+}
+
 func isIpv4(ip string) bool {
 	if net.ParseIP(ip) != nil {
 		for i := 0; i < len(ip); i++ {
@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/* a problem of virtual-screen-def.json, Path is the JSON path such as "real_displays[1].vdisplay_id" */
+type VScrnDefIssue struct {
+	Line    int
+	Path    string
+	Message string
+}
+
+type VScrnDefError struct {
+	File   string
+	Issues []VScrnDefIssue
+}
+
+func (issue VScrnDefIssue) String() string {
+	if issue.Path == "" {
+		return issue.Message
+	}
+	return issue.Path + ": " + issue.Message
+}
+
+/* one issue per line in the form of "file:line: path: message" */
+func (verr *VScrnDefError) Error() string {
+	msgs := make([]string, 0)
+	for _, issue := range verr.Issues {
+		msgs = append(msgs, verr.File+":"+strconv.Itoa(issue.Line)+": "+issue.String())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func offsetToLine(jsonBytes []byte, offset int64) int {
+	if offset > int64(len(jsonBytes)) {
+		offset = int64(len(jsonBytes))
+	}
+	return bytes.Count(jsonBytes[:offset], []byte("\n")) + 1
+}
+
+/* the decoder offset is at the end of the previous token, so the separators before the value are skipped */
+func skipSeparators(jsonBytes []byte, offset int64) int64 {
+	for offset < int64(len(jsonBytes)) && strings.IndexByte(" \t\r\n,:", jsonBytes[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+/* maps each JSON path to the line where its value starts */
+func indexJsonLines(jsonBytes []byte) map[string]int {
+	lines := make(map[string]int)
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		lines[path] = offsetToLine(jsonBytes, skipSeparators(jsonBytes, dec.InputOffset()))
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+
+		switch delim {
+		case '{':
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyToken.(string)
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				err = walk(childPath)
+				if err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				err = walk(fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+	walk("")
+
+	return lines
+}
+
+/* the line of the path, or of its nearest parent if the key is omitted */
+func lookupLine(lines map[string]int, path string) int {
+	for {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return lines[""]
+		}
+		path = path[:i]
+	}
+}
+
+func newDecodeError(fname string, jsonBytes []byte, err error) error {
+	issue := VScrnDefIssue{
+		Message: "json decode error: " + err.Error(),
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		issue.Line = offsetToLine(jsonBytes, syntaxErr.Offset)
+	} else if errors.As(err, &typeErr) {
+		issue.Line = offsetToLine(jsonBytes, typeErr.Offset)
+		issue.Path = arrayIndexPattern.ReplaceAllString(typeErr.Field, "[$1]")
+	}
+
+	return &VScrnDefError{
+		File:   fname,
+		Issues: []VScrnDefIssue{issue},
+	}
+}
+
+/* UnmarshalTypeError.Field has the array index as "node.0.node_id" */
+var arrayIndexPattern = regexp.MustCompile(`\.(\d+)`)
+
+type vscrnDefChecker struct {
+	issues []VScrnDefIssue
+}
+
+func (checker *vscrnDefChecker) add(path string, format string, args ...interface{}) {
+	checker.issues = append(checker.issues, VScrnDefIssue{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/* the port is shared by the nodes which have the same ip */
+type portUser struct {
+	path string
+	what string
+}
+
+func (checker *vscrnDefChecker) usePort(ports map[string]map[int]portUser, host string, port int, user portUser) {
+	if _, ok := ports[host]; !ok {
+		ports[host] = make(map[int]portUser)
+	}
+	if prev, ok := ports[host][port]; ok {
+		checker.add(user.path, "port %d of %s collides with %s (%s)", port, user.what, prev.what, prev.path)
+		return
+	}
+	ports[host][port] = user
+}
+
+/*
+ * Checks the consistency of the definition. The issues have no line, it is
+ * filled by ValidateVScrnDefBytes.
+ */
+func (vdef *VScrnDef) Validate() []VScrnDefIssue {
+	checker := &vscrnDefChecker{issues: make([]VScrnDefIssue, 0)}
+
+	size := vdef.Def2D.Size
+	if size.VirtualW <= 0 || size.VirtualH <= 0 {
+		checker.add("virtual_screen_2d.size", "zero-sized virtual screen (%dx%d)", size.VirtualW, size.VirtualH)
+	}
+
+	vdisplayPaths := make(map[int]string)
+	for i, vdisp := range vdef.Def2D.VirtualDisplays {
+		path := fmt.Sprintf("virtual_screen_2d.virtual_displays[%d]", i)
+		if prev, ok := vdisplayPaths[vdisp.VDisplayId]; ok {
+			checker.add(path+".vdisplay_id", "duplicate vdisplay_id %d (also in %s)", vdisp.VDisplayId, prev)
+		} else {
+			vdisplayPaths[vdisp.VDisplayId] = path
+		}
+
+		if vdisp.VirtualW <= 0 || vdisp.VirtualH <= 0 {
+			checker.add(path, "zero-sized virtual display %d (%dx%d)", vdisp.VDisplayId, vdisp.VirtualW, vdisp.VirtualH)
+		} else if vdisp.VirtualX < 0 || vdisp.VirtualY < 0 ||
+			vdisp.VirtualX+vdisp.VirtualW > size.VirtualW || vdisp.VirtualY+vdisp.VirtualH > size.VirtualH {
+			checker.add(path, "virtual display %d (%d,%d %dx%d) is outside virtual_screen_2d.size (%dx%d)",
+				vdisp.VDisplayId, vdisp.VirtualX, vdisp.VirtualY, vdisp.VirtualW, vdisp.VirtualH, size.VirtualW, size.VirtualH)
+		}
+	}
+
+	nodePaths := make(map[int]string)
+	for i, node := range vdef.Nodes {
+		path := fmt.Sprintf("node[%d]", i)
+		if prev, ok := nodePaths[node.NodeId]; ok {
+			checker.add(path+".node_id", "duplicate node_id %d (also in %s)", node.NodeId, prev)
+		} else {
+			nodePaths[node.NodeId] = path
+		}
+	}
+
+	/* rdisplay_id is the id of the screen in the compositor, so it is unique in each node */
+	rdisplayPaths := make(map[[2]int]string)
+	for i, rdisp := range vdef.RealDisplays {
+		path := fmt.Sprintf("real_displays[%d]", i)
+		if _, ok := vdisplayPaths[rdisp.VDisplayId]; !ok {
+			checker.add(path+".vdisplay_id", "unknown vdisplay_id %d", rdisp.VDisplayId)
+		}
+		if _, ok := nodePaths[rdisp.NodeId]; !ok {
+			checker.add(path+".node_id", "unknown node_id %d", rdisp.NodeId)
+		}
+		if rdisp.PixelW <= 0 || rdisp.PixelH <= 0 {
+			checker.add(path, "zero-sized real display %d (%dx%d)", rdisp.RDisplayId, rdisp.PixelW, rdisp.PixelH)
+		}
+		if !IsValidTransform(rdisp.Transform) {
+			checker.add(path+".transform", "unknown transform %q (expected one of %s)",
+				rdisp.Transform, strings.Join(GetTransforms(), ", "))
+		}
+
+		key := [2]int{rdisp.NodeId, rdisp.RDisplayId}
+		if prev, ok := rdisplayPaths[key]; ok {
+			checker.add(path+".rdisplay_id", "duplicate rdisplay_id %d on node %d (also in %s)", rdisp.RDisplayId, rdisp.NodeId, prev)
+		} else {
+			rdisplayPaths[key] = path
+		}
+	}
+
+	nodeHost := func(nodeId int) string {
+		for _, node := range vdef.Nodes {
+			if node.NodeId == nodeId && node.Ip != "" {
+				return node.Ip
+			}
+		}
+		return "node " + strconv.Itoa(nodeId)
+	}
+	ports := make(map[string]map[int]portUser)
+
+	dws := vdef.DistributedWindowSystem
+	if _, ok := nodePaths[dws.ULAClientManager.NodeId]; !ok {
+		checker.add("distributed_window_system.ula_client_manager.node_id", "unknown node_id %d", dws.ULAClientManager.NodeId)
+	}
+	if dws.ULAClientManager.Port > 0 {
+		checker.usePort(ports, nodeHost(dws.ULAClientManager.NodeId), dws.ULAClientManager.Port, portUser{
+			path: "distributed_window_system.ula_client_manager.port",
+			what: "ula_client_manager",
+		})
+	}
+
+	frameworkPaths := make(map[int]string)
+	for i, fwn := range dws.FrameworkNode {
+		path := fmt.Sprintf("distributed_window_system.framework_node[%d]", i)
+		if prev, ok := frameworkPaths[fwn.NodeId]; ok {
+			checker.add(path+".node_id", "duplicate node_id %d (also in %s)", fwn.NodeId, prev)
+		} else {
+			frameworkPaths[fwn.NodeId] = path
+		}
+		if _, ok := nodePaths[fwn.NodeId]; !ok {
+			checker.add(path+".node_id", "framework node %d has no matching node entry", fwn.NodeId)
+		}
+
+		host := nodeHost(fwn.NodeId)
+		if fwn.Ula.Port <= 0 {
+			checker.add(path+".ula.port", "port of ula-node %d is not set", fwn.NodeId)
+		} else {
+			checker.usePort(ports, host, fwn.Ula.Port, portUser{
+				path: path + ".ula.port",
+				what: fmt.Sprintf("ula-node %d", fwn.NodeId),
+			})
+		}
+		if fwn.Ula.Debug {
+			checker.usePort(ports, host, fwn.Ula.DebugPort, portUser{
+				path: path + ".ula.debug_port",
+				what: fmt.Sprintf("debug endpoint of ula-node %d", fwn.NodeId),
+			})
+		}
+
+		comCounts := make(map[int]int)
+		for j, com := range fwn.Compositor {
+			comPath := fmt.Sprintf("%s.compositor[%d]", path, j)
+			if len(com.VDisplayIds) == 0 {
+				checker.add(comPath+".vdisplay_ids", "compositor has no vdisplay_ids")
+			} else {
+				comCounts[com.VDisplayIds[0]]++
+			}
+			for k, vDisplayId := range com.VDisplayIds {
+				if !vdef.IsVDisplayInNode(fwn.NodeId, vDisplayId) {
+					checker.add(fmt.Sprintf("%s.vdisplay_ids[%d]", comPath, k),
+						"vdisplay_id %d is not mapped to node %d in real_displays", vDisplayId, fwn.NodeId)
+				}
+			}
+		}
+
+		/* each rdisplay of a mirrored vdisplay needs its own compositor */
+		if len(fwn.Compositor) > 0 {
+			rdispCounts := make(map[int]int)
+			for _, rdisp := range vdef.RealDisplays {
+				if rdisp.NodeId == fwn.NodeId {
+					rdispCounts[rdisp.VDisplayId]++
+				}
+			}
+			for _, vdisp := range vdef.Def2D.VirtualDisplays {
+				rdispCount := rdispCounts[vdisp.VDisplayId]
+				if rdispCount > 1 && comCounts[vdisp.VDisplayId] < rdispCount {
+					checker.add(path+".compositor", "vdisplay_id %d is mirrored to %d rdisplays on node %d but has %d compositors",
+						vdisp.VDisplayId, rdispCount, fwn.NodeId, comCounts[vdisp.VDisplayId])
+				}
+			}
+		}
+	}
+
+	return checker.issues
+}
+
+/* decodes and validates the definition, and the error is VScrnDefError with the line of each issue */
+func ValidateVScrnDefBytes(fname string, jsonBytes []byte) (*VScrnDef, error) {
+	vscrnDef, err := decodeVScrnDef(fname, jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := vscrnDef.Validate()
+	if len(issues) == 0 {
+		return vscrnDef, nil
+	}
+
+	lines := indexJsonLines(jsonBytes)
+	for i := range issues {
+		issues[i].Line = lookupLine(lines, issues[i].Path)
+	}
+	return nil, &VScrnDefError{
+		File:   fname,
+		Issues: issues,
+	}
+}
+
+/* the strict version of ReadVScrnDef */
+func ValidateVScrnDef(vsdPath ...string) (*VScrnDef, error) {
+	fname, jsonBytes, err := readVScrnDefFile(vsdPath...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ValidateVScrnDefBytes(fname, jsonBytes)
+}
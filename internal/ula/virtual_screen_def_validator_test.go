@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+/**
+ * Copyright (c) 2024  Panasonic Automotive Systems, Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ula
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const TEST_VSD_PATH = "../../example/vsd/iviwinmgr/virtual-screen-def.json"
+
+func TestValidateVScrnDefBytes(t *testing.T) {
+	base, err := os.ReadFile(TEST_VSD_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type replacement struct {
+		old string
+		new string
+	}
+	tests := []struct {
+		name         string
+		replacements []replacement
+		want         []VScrnDefIssue
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "duplicate vdisplay_id",
+			replacements: []replacement{
+				{`"vdisplay_id": 1,
+        "disp_name": "SCREEN1"`, `"vdisplay_id": 0,
+        "disp_name": "SCREEN1"`},
+			},
+			want: []VScrnDefIssue{
+				{17, "virtual_screen_2d.virtual_displays[1].vdisplay_id", "duplicate vdisplay_id 0"},
+				{36, "real_displays[1].vdisplay_id", "unknown vdisplay_id 1"},
+			},
+		},
+		{
+			name: "unknown transform",
+			replacements: []replacement{
+				{`"rdisplay_id": 1
+`, `"rdisplay_id": 1, "transform": "45"
+`},
+			},
+			want: []VScrnDefIssue{
+				{39, "real_displays[1].transform", `unknown transform "45"`},
+			},
+		},
+		{
+			name: "issue of the object is on the line of the object",
+			replacements: []replacement{
+				{`"pixel_w": 1920,
+      "pixel_h": 1080,
+      "rdisplay_id": 0`, `"pixel_w": 0,
+      "pixel_h": 1080,
+      "rdisplay_id": 0`},
+			},
+			want: []VScrnDefIssue{
+				{27, "real_displays[0]", "zero-sized real display 0"},
+			},
+		},
+		{
+			name: "port collision",
+			replacements: []replacement{
+				{`"port": 10100`, `"port": 6443`},
+			},
+			want: []VScrnDefIssue{
+				{64, "distributed_window_system.framework_node[0].ula.port", "port 6443 of ula-node 0 collides with ula_client_manager"},
+			},
+		},
+		{
+			name: "syntax error",
+			replacements: []replacement{
+				{`"hostname": "host1",`, `"hostname": "host1"`},
+			},
+			want: []VScrnDefIssue{
+				{46, "", "json decode error: invalid character"},
+			},
+		},
+		{
+			name: "type error",
+			replacements: []replacement{
+				{`"node_id": 0,
+      "hostname"`, `"node_id": "0",
+      "hostname"`},
+			},
+			want: []VScrnDefIssue{
+				{44, "node[0].node_id", "json decode error: json: cannot unmarshal string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonStr := string(base)
+			for _, r := range tt.replacements {
+				if !strings.Contains(jsonStr, r.old) {
+					t.Fatalf("%q is not in %s", r.old, TEST_VSD_PATH)
+				}
+				jsonStr = strings.Replace(jsonStr, r.old, r.new, 1)
+			}
+
+			vdef, err := ValidateVScrnDefBytes("test.json", []byte(jsonStr))
+			if tt.want == nil {
+				if err != nil || vdef == nil {
+					t.Fatalf("expects no error, got %v", err)
+				}
+				return
+			}
+
+			var verr *VScrnDefError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expects VScrnDefError, got %v", err)
+			}
+			if len(verr.Issues) != len(tt.want) {
+				t.Fatalf("issues = %v, want %v", verr.Issues, tt.want)
+			}
+			for i, issue := range verr.Issues {
+				want := tt.want[i]
+				if issue.Line != want.Line || issue.Path != want.Path || !strings.HasPrefix(issue.Message, want.Message) {
+					t.Errorf("issue = %+v, want %+v", issue, want)
+				}
+			}
+		})
+	}
+}
+
+/* the error has one "file:line: path: message" per issue */
+func TestVScrnDefErrorString(t *testing.T) {
+	verr := &VScrnDefError{
+		File: "test.json",
+		Issues: []VScrnDefIssue{
+			{Line: 3, Path: "node[0].node_id", Message: "duplicate node_id 0"},
+			{Line: 1, Message: "json decode error"},
+		},
+	}
+	want := []string{
+		"test.json:3: node[0].node_id: duplicate node_id 0",
+		"test.json:1: json decode error",
+	}
+	if got := strings.Split(verr.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -37,29 +37,996 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ResultCode int32
+
+const (
+	ResultCode_RESULT_OK                 ResultCode = 0
+	ResultCode_RESULT_PARSE_ERROR        ResultCode = 1
+	ResultCode_RESULT_VALIDATION_ERROR   ResultCode = 2
+	ResultCode_RESULT_NODE_UNREACHABLE   ResultCode = 3
+	ResultCode_RESULT_NODE_TIMEOUT       ResultCode = 4
+	ResultCode_RESULT_COMPOSITOR_FAILURE ResultCode = 5
+	ResultCode_RESULT_INTERNAL_ERROR     ResultCode = 6
+)
+
+// Enum value maps for ResultCode.
+var (
+	ResultCode_name = map[int32]string{
+		0: "RESULT_OK",
+		1: "RESULT_PARSE_ERROR",
+		2: "RESULT_VALIDATION_ERROR",
+		3: "RESULT_NODE_UNREACHABLE",
+		4: "RESULT_NODE_TIMEOUT",
+		5: "RESULT_COMPOSITOR_FAILURE",
+		6: "RESULT_INTERNAL_ERROR",
+	}
+	ResultCode_value = map[string]int32{
+		"RESULT_OK":                 0,
+		"RESULT_PARSE_ERROR":        1,
+		"RESULT_VALIDATION_ERROR":   2,
+		"RESULT_NODE_UNREACHABLE":   3,
+		"RESULT_NODE_TIMEOUT":       4,
+		"RESULT_COMPOSITOR_FAILURE": 5,
+		"RESULT_INTERNAL_ERROR":     6,
+	}
+)
+
+func (x ResultCode) Enum() *ResultCode {
+	p := new(ResultCode)
+	*p = x
+	return p
+}
+
+func (x ResultCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResultCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_dwm_proto_enumTypes[0].Descriptor()
+}
+
+func (ResultCode) Type() protoreflect.EnumType {
+	return &file_proto_dwm_proto_enumTypes[0]
+}
+
+func (x ResultCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResultCode.Descriptor instead.
+func (ResultCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{0}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
+func (x *Empty) Reset() {
+	*x = Empty{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{0}
+}
+
+type SetLayoutCommandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LayoutCommand string `protobuf:"bytes,1,opt,name=layout_command,json=layoutCommand,proto3" json:"layout_command,omitempty"`
+	RequestId     string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // generated by the server if it is empty
+	DryRun        bool   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`         // only generate the commands of the nodes and approximate the ones of the compositors
+}
+
+func (x *SetLayoutCommandRequest) Reset() {
+	*x = SetLayoutCommandRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetLayoutCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLayoutCommandRequest) ProtoMessage() {}
+
+func (x *SetLayoutCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLayoutCommandRequest.ProtoReflect.Descriptor instead.
+func (*SetLayoutCommandRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetLayoutCommandRequest) GetLayoutCommand() string {
+	if x != nil {
+		return x.LayoutCommand
+	}
+	return ""
+}
+
+func (x *SetLayoutCommandRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SetLayoutCommandRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type NodeResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId     int32      `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	TargetAddr string     `protobuf:"bytes,2,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
+	Result     int32      `protobuf:"varint,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error      string     `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	LatencyUs  int64      `protobuf:"varint,5,opt,name=latency_us,json=latencyUs,proto3" json:"latency_us,omitempty"`
+	TimedOut   bool       `protobuf:"varint,6,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	Code       ResultCode `protobuf:"varint,7,opt,name=code,proto3,enum=dwm.ResultCode" json:"code,omitempty"`
+	Phase      string     `protobuf:"bytes,8,opt,name=phase,proto3" json:"phase,omitempty"`
+}
+
+func (x *NodeResult) Reset() {
+	*x = NodeResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeResult) ProtoMessage() {}
+
+func (x *NodeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeResult.ProtoReflect.Descriptor instead.
+func (*NodeResult) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeResult) GetNodeId() int32 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *NodeResult) GetTargetAddr() string {
+	if x != nil {
+		return x.TargetAddr
+	}
+	return ""
+}
+
+func (x *NodeResult) GetResult() int32 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
+func (x *NodeResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NodeResult) GetLatencyUs() int64 {
+	if x != nil {
+		return x.LatencyUs
+	}
+	return 0
+}
+
+func (x *NodeResult) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+func (x *NodeResult) GetCode() ResultCode {
+	if x != nil {
+		return x.Code
+	}
+	return ResultCode_RESULT_OK
+}
+
+func (x *NodeResult) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+type CompositorCommand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Compositor  string   `protobuf:"bytes,1,opt,name=compositor,proto3" json:"compositor,omitempty"` // socket of uhmi-ivi-wm or rvgpu-compositor
+	RdisplayIds []int32  `protobuf:"varint,2,rep,packed,name=rdisplay_ids,json=rdisplayIds,proto3" json:"rdisplay_ids,omitempty"`
+	Messages    []string `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"` // json generated by the manager for the compositor, in order. The split layer IDs and the diffs are approximated from the layout applied by the manager
+}
+
+func (x *CompositorCommand) Reset() {
+	*x = CompositorCommand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompositorCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositorCommand) ProtoMessage() {}
+
+func (x *CompositorCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositorCommand.ProtoReflect.Descriptor instead.
+func (*CompositorCommand) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CompositorCommand) GetCompositor() string {
+	if x != nil {
+		return x.Compositor
+	}
+	return ""
+}
+
+func (x *CompositorCommand) GetRdisplayIds() []int32 {
+	if x != nil {
+		return x.RdisplayIds
+	}
+	return nil
+}
+
+func (x *CompositorCommand) GetMessages() []string {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type DryRunNodeResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId             int32                `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	PluginType         string               `protobuf:"bytes,2,opt,name=plugin_type,json=pluginType,proto3" json:"plugin_type,omitempty"`
+	ApplyCommandData   string               `protobuf:"bytes,3,opt,name=apply_command_data,json=applyCommandData,proto3" json:"apply_command_data,omitempty"` // json of ApplyCommandData which the node would receive
+	CompositorCommands []*CompositorCommand `protobuf:"bytes,4,rep,name=compositor_commands,json=compositorCommands,proto3" json:"compositor_commands,omitempty"`
+}
+
+func (x *DryRunNodeResult) Reset() {
+	*x = DryRunNodeResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DryRunNodeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunNodeResult) ProtoMessage() {}
+
+func (x *DryRunNodeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunNodeResult.ProtoReflect.Descriptor instead.
+func (*DryRunNodeResult) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DryRunNodeResult) GetNodeId() int32 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *DryRunNodeResult) GetPluginType() string {
+	if x != nil {
+		return x.PluginType
+	}
+	return ""
+}
+
+func (x *DryRunNodeResult) GetApplyCommandData() string {
+	if x != nil {
+		return x.ApplyCommandData
+	}
+	return ""
+}
+
+func (x *DryRunNodeResult) GetCompositorCommands() []*CompositorCommand {
+	if x != nil {
+		return x.CompositorCommands
+	}
+	return nil
+}
+
+type Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status        string              `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	NodeResults   []*NodeResult       `protobuf:"bytes,2,rep,name=node_results,json=nodeResults,proto3" json:"node_results,omitempty"`
+	Succeeded     bool                `protobuf:"varint,3,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Code          ResultCode          `protobuf:"varint,4,opt,name=code,proto3,enum=dwm.ResultCode" json:"code,omitempty"`
+	RequestId     string              `protobuf:"bytes,5,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	DryRunResults []*DryRunNodeResult `protobuf:"bytes,6,rep,name=dry_run_results,json=dryRunResults,proto3" json:"dry_run_results,omitempty"`
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Response) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Response) GetNodeResults() []*NodeResult {
+	if x != nil {
+		return x.NodeResults
+	}
+	return nil
+}
+
+func (x *Response) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *Response) GetCode() ResultCode {
+	if x != nil {
+		return x.Code
+	}
+	return ResultCode_RESULT_OK
+}
+
+func (x *Response) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Response) GetDryRunResults() []*DryRunNodeResult {
+	if x != nil {
+		return x.DryRunResults
+	}
+	return nil
+}
+
+type GetLayoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WithNodePixelScreens bool    `protobuf:"varint,1,opt,name=with_node_pixel_screens,json=withNodePixelScreens,proto3" json:"with_node_pixel_screens,omitempty"`
+	NodeIds              []int32 `protobuf:"varint,2,rep,packed,name=node_ids,json=nodeIds,proto3" json:"node_ids,omitempty"` // all nodes if it is empty
+}
+
+func (x *GetLayoutRequest) Reset() {
+	*x = GetLayoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLayoutRequest) ProtoMessage() {}
+
+func (x *GetLayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLayoutRequest.ProtoReflect.Descriptor instead.
+func (*GetLayoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetLayoutRequest) GetWithNodePixelScreens() bool {
+	if x != nil {
+		return x.WithNodePixelScreens
+	}
+	return false
+}
+
+func (x *GetLayoutRequest) GetNodeIds() []int32 {
+	if x != nil {
+		return x.NodeIds
+	}
+	return nil
+}
+
+type NodePixelScreens struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId       int32  `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	PixelScreens string `protobuf:"bytes,2,opt,name=pixel_screens,json=pixelScreens,proto3" json:"pixel_screens,omitempty"` // json of NodePixelScreens
+}
+
+func (x *NodePixelScreens) Reset() {
+	*x = NodePixelScreens{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodePixelScreens) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodePixelScreens) ProtoMessage() {}
+
+func (x *NodePixelScreens) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodePixelScreens.ProtoReflect.Descriptor instead.
+func (*NodePixelScreens) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NodePixelScreens) GetNodeId() int32 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *NodePixelScreens) GetPixelScreens() string {
+	if x != nil {
+		return x.PixelScreens
+	}
+	return ""
+}
+
+type GetLayoutResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status           string              `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Layout           string              `protobuf:"bytes,2,opt,name=layout,proto3" json:"layout,omitempty"` // json in the vocabulary of initial_vscreen
+	NodePixelScreens []*NodePixelScreens `protobuf:"bytes,3,rep,name=node_pixel_screens,json=nodePixelScreens,proto3" json:"node_pixel_screens,omitempty"`
+	Generation       uint64              `protobuf:"varint,4,opt,name=generation,proto3" json:"generation,omitempty"`
+}
+
+func (x *GetLayoutResponse) Reset() {
+	*x = GetLayoutResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLayoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLayoutResponse) ProtoMessage() {}
+
+func (x *GetLayoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLayoutResponse.ProtoReflect.Descriptor instead.
+func (*GetLayoutResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetLayoutResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetLayoutResponse) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+func (x *GetLayoutResponse) GetNodePixelScreens() []*NodePixelScreens {
+	if x != nil {
+		return x.NodePixelScreens
+	}
+	return nil
+}
+
+func (x *GetLayoutResponse) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type IdPair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LayerId   int32 `protobuf:"varint,1,opt,name=layer_id,json=layerId,proto3" json:"layer_id,omitempty"`
+	SurfaceId int32 `protobuf:"varint,2,opt,name=surface_id,json=surfaceId,proto3" json:"surface_id,omitempty"` // -1 means the layer itself
+}
+
+func (x *IdPair) Reset() {
+	*x = IdPair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IdPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdPair) ProtoMessage() {}
+
+func (x *IdPair) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdPair.ProtoReflect.Descriptor instead.
+func (*IdPair) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *IdPair) GetLayerId() int32 {
+	if x != nil {
+		return x.LayerId
+	}
+	return 0
+}
+
+func (x *IdPair) GetSurfaceId() int32 {
+	if x != nil {
+		return x.SurfaceId
+	}
+	return 0
+}
+
+type LayoutEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Generation uint64    `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	Command    string    `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	ChgIds     []*IdPair `protobuf:"bytes,3,rep,name=chg_ids,json=chgIds,proto3" json:"chg_ids,omitempty"`
+}
+
+func (x *LayoutEvent) Reset() {
+	*x = LayoutEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LayoutEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LayoutEvent) ProtoMessage() {}
+
+func (x *LayoutEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LayoutEvent.ProtoReflect.Descriptor instead.
+func (*LayoutEvent) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LayoutEvent) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *LayoutEvent) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *LayoutEvent) GetChgIds() []*IdPair {
+	if x != nil {
+		return x.ChgIds
+	}
+	return nil
+}
+
+type UndoLayoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // generated by the server if it is empty
+}
+
+func (x *UndoLayoutRequest) Reset() {
+	*x = UndoLayoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UndoLayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoLayoutRequest) ProtoMessage() {}
+
+func (x *UndoLayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoLayoutRequest.ProtoReflect.Descriptor instead.
+func (*UndoLayoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UndoLayoutRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type RevertToGenerationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Generation uint64 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	RequestId  string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // generated by the server if it is empty
+}
+
+func (x *RevertToGenerationRequest) Reset() {
+	*x = RevertToGenerationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RevertToGenerationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertToGenerationRequest) ProtoMessage() {}
+
+func (x *RevertToGenerationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertToGenerationRequest.ProtoReflect.Descriptor instead.
+func (*RevertToGenerationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RevertToGenerationRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *RevertToGenerationRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type RDisplayStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RdisplayId       int32 `protobuf:"varint,1,opt,name=rdisplay_id,json=rdisplayId,proto3" json:"rdisplay_id,omitempty"`
+	Connected        bool  `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"` // the compositor of the real display is connected
+	Reconnects       int32 `protobuf:"varint,3,opt,name=reconnects,proto3" json:"reconnects,omitempty"`
+	LastResyncResult int32 `protobuf:"varint,4,opt,name=last_resync_result,json=lastResyncResult,proto3" json:"last_resync_result,omitempty"`
+}
+
+func (x *RDisplayStatus) Reset() {
+	*x = RDisplayStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_dwm_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RDisplayStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RDisplayStatus) ProtoMessage() {}
+
+func (x *RDisplayStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RDisplayStatus.ProtoReflect.Descriptor instead.
+func (*RDisplayStatus) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RDisplayStatus) GetRdisplayId() int32 {
+	if x != nil {
+		return x.RdisplayId
+	}
+	return 0
+}
+
+func (x *RDisplayStatus) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *RDisplayStatus) GetReconnects() int32 {
+	if x != nil {
+		return x.Reconnects
+	}
+	return 0
+}
+
+func (x *RDisplayStatus) GetLastResyncResult() int32 {
+	if x != nil {
+		return x.LastResyncResult
+	}
+	return 0
+}
+
+type NodeStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId     int32             `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	TargetAddr string            `protobuf:"bytes,2,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
+	Reachable  bool              `protobuf:"varint,3,opt,name=reachable,proto3" json:"reachable,omitempty"`
+	Error      string            `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	PluginType string            `protobuf:"bytes,5,opt,name=plugin_type,json=pluginType,proto3" json:"plugin_type,omitempty"`
+	Generation uint64            `protobuf:"varint,6,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the layout which the node applied last
+	UptimeSec  int64             `protobuf:"varint,7,opt,name=uptime_sec,json=uptimeSec,proto3" json:"uptime_sec,omitempty"`
+	Rdisplays  []*RDisplayStatus `protobuf:"bytes,8,rep,name=rdisplays,proto3" json:"rdisplays,omitempty"`
+}
+
+func (x *NodeStatus) Reset() {
+	*x = NodeStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_dwm_proto_msgTypes[0]
+		mi := &file_proto_dwm_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Empty) String() string {
+func (x *NodeStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*NodeStatus) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dwm_proto_msgTypes[0]
+func (x *NodeStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -70,36 +1037,94 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
-	return file_proto_dwm_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use NodeStatus.ProtoReflect.Descriptor instead.
+func (*NodeStatus) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{14}
 }
 
-type SetLayoutCommandRequest struct {
+func (x *NodeStatus) GetNodeId() int32 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *NodeStatus) GetTargetAddr() string {
+	if x != nil {
+		return x.TargetAddr
+	}
+	return ""
+}
+
+func (x *NodeStatus) GetReachable() bool {
+	if x != nil {
+		return x.Reachable
+	}
+	return false
+}
+
+func (x *NodeStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NodeStatus) GetPluginType() string {
+	if x != nil {
+		return x.PluginType
+	}
+	return ""
+}
+
+func (x *NodeStatus) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *NodeStatus) GetUptimeSec() int64 {
+	if x != nil {
+		return x.UptimeSec
+	}
+	return 0
+}
+
+func (x *NodeStatus) GetRdisplays() []*RDisplayStatus {
+	if x != nil {
+		return x.Rdisplays
+	}
+	return nil
+}
+
+type ClusterStatusResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	LayoutCommand string `protobuf:"bytes,1,opt,name=layout_command,json=layoutCommand,proto3" json:"layout_command,omitempty"`
+	Status     string        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Generation uint64        `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the layout in ula-client
+	Nodes      []*NodeStatus `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"`
 }
 
-func (x *SetLayoutCommandRequest) Reset() {
-	*x = SetLayoutCommandRequest{}
+func (x *ClusterStatusResponse) Reset() {
+	*x = ClusterStatusResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_dwm_proto_msgTypes[1]
+		mi := &file_proto_dwm_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SetLayoutCommandRequest) String() string {
+func (x *ClusterStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetLayoutCommandRequest) ProtoMessage() {}
+func (*ClusterStatusResponse) ProtoMessage() {}
 
-func (x *SetLayoutCommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dwm_proto_msgTypes[1]
+func (x *ClusterStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -110,43 +1135,61 @@ func (x *SetLayoutCommandRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetLayoutCommandRequest.ProtoReflect.Descriptor instead.
-func (*SetLayoutCommandRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dwm_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ClusterStatusResponse.ProtoReflect.Descriptor instead.
+func (*ClusterStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *SetLayoutCommandRequest) GetLayoutCommand() string {
+func (x *ClusterStatusResponse) GetStatus() string {
 	if x != nil {
-		return x.LayoutCommand
+		return x.Status
 	}
 	return ""
 }
 
-type Response struct {
+func (x *ClusterStatusResponse) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *ClusterStatusResponse) GetNodes() []*NodeStatus {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type NodeStateEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	NodeId      int32  `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	TargetAddr  string `protobuf:"bytes,2,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
+	Up          bool   `protobuf:"varint,3,opt,name=up,proto3" json:"up,omitempty"`
+	Reason      string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	SinceUnixMs int64  `protobuf:"varint,5,opt,name=since_unix_ms,json=sinceUnixMs,proto3" json:"since_unix_ms,omitempty"` // time when the node went up or down
 }
 
-func (x *Response) Reset() {
-	*x = Response{}
+func (x *NodeStateEvent) Reset() {
+	*x = NodeStateEvent{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_dwm_proto_msgTypes[2]
+		mi := &file_proto_dwm_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Response) String() string {
+func (x *NodeStateEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Response) ProtoMessage() {}
+func (*NodeStateEvent) ProtoMessage() {}
 
-func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dwm_proto_msgTypes[2]
+func (x *NodeStateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dwm_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -157,30 +1200,208 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Response.ProtoReflect.Descriptor instead.
-func (*Response) Descriptor() ([]byte, []int) {
-	return file_proto_dwm_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use NodeStateEvent.ProtoReflect.Descriptor instead.
+func (*NodeStateEvent) Descriptor() ([]byte, []int) {
+	return file_proto_dwm_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *Response) GetStatus() string {
+func (x *NodeStateEvent) GetNodeId() int32 {
 	if x != nil {
-		return x.Status
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *NodeStateEvent) GetTargetAddr() string {
+	if x != nil {
+		return x.TargetAddr
+	}
+	return ""
+}
+
+func (x *NodeStateEvent) GetUp() bool {
+	if x != nil {
+		return x.Up
+	}
+	return false
+}
+
+func (x *NodeStateEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
+func (x *NodeStateEvent) GetSinceUnixMs() int64 {
+	if x != nil {
+		return x.SinceUnixMs
+	}
+	return 0
+}
+
 var File_proto_dwm_proto protoreflect.FileDescriptor
 
 var file_proto_dwm_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x64, 0x77, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x12, 0x03, 0x64, 0x77, 0x6d, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
-	0x40, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
+	0x78, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
 	0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61,
 	0x79, 0x6f, 0x75, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0d, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x22, 0x22, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x32, 0x81, 0x01, 0x0a, 0x0a, 0x44, 0x77, 0x6d, 0x53, 0x65, 0x72,
+	0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0xeb, 0x01, 0x0a, 0x0a, 0x4e, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49,
+	0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x41, 0x64,
+	0x64, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x75, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x55, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x4f, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x64, 0x77, 0x6d,
+	0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x22, 0x72, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x12, 0x21, 0x0a, 0x0c,
+	0x72, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x05, 0x52, 0x0b, 0x72, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x49, 0x64, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0xc3, 0x01, 0x0a, 0x10,
+	0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6c, 0x75,
+	0x67, 0x69, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x61, 0x70,
+	0x70, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61, 0x12, 0x47, 0x0a, 0x13, 0x63, 0x6f, 0x6d, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x12, 0x63,
+	0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x73, 0x22, 0xf7, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x32, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x64,
+	0x77, 0x6d, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0b, 0x6e,
+	0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x75,
+	0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x3d, 0x0a, 0x0f,
+	0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x44, 0x72, 0x79, 0x52,
+	0x75, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0d, 0x64, 0x72,
+	0x79, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x64, 0x0a, 0x10, 0x47,
+	0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x35, 0x0a, 0x17, 0x77, 0x69, 0x74, 0x68, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x70, 0x69, 0x78,
+	0x65, 0x6c, 0x5f, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x14, 0x77, 0x69, 0x74, 0x68, 0x4e, 0x6f, 0x64, 0x65, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x53,
+	0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64,
+	0x73, 0x22, 0x50, 0x0a, 0x10, 0x4e, 0x6f, 0x64, 0x65, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x53, 0x63,
+	0x72, 0x65, 0x65, 0x6e, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x23,
+	0x0a, 0x0d, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x5f, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x53, 0x63, 0x72, 0x65,
+	0x65, 0x6e, 0x73, 0x22, 0xa8, 0x01, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x43, 0x0a, 0x12, 0x6e, 0x6f, 0x64,
+	0x65, 0x5f, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x5f, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x4e, 0x6f, 0x64, 0x65,
+	0x50, 0x69, 0x78, 0x65, 0x6c, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x52, 0x10, 0x6e, 0x6f,
+	0x64, 0x65, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x12, 0x1e,
+	0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x42,
+	0x0a, 0x06, 0x49, 0x64, 0x50, 0x61, 0x69, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x75, 0x72, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x73, 0x75, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x49, 0x64, 0x22, 0x6d, 0x0a, 0x0b, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x24, 0x0a, 0x07, 0x63,
+	0x68, 0x67, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x64,
+	0x77, 0x6d, 0x2e, 0x49, 0x64, 0x50, 0x61, 0x69, 0x72, 0x52, 0x06, 0x63, 0x68, 0x67, 0x49, 0x64,
+	0x73, 0x22, 0x32, 0x0a, 0x11, 0x55, 0x6e, 0x64, 0x6f, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x5a, 0x0a, 0x19, 0x52, 0x65, 0x76, 0x65, 0x72, 0x74, 0x54,
+	0x6f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49,
+	0x64, 0x22, 0x9d, 0x01, 0x0a, 0x0e, 0x52, 0x44, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x72, 0x64, 0x69, 0x73, 0x70,
+	0x6c, 0x61, 0x79, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x79,
+	0x6e, 0x63, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x10, 0x6c, 0x61, 0x73, 0x74, 0x52, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x22, 0x8d, 0x02, 0x0a, 0x0a, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65,
+	0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72,
+	0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1f,
+	0x0a, 0x0b, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x1d, 0x0a, 0x0a, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x63, 0x12, 0x31,
+	0x0a, 0x09, 0x72, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x52, 0x44, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x09, 0x72, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x73, 0x22, 0x76, 0x0a, 0x15, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x96, 0x01, 0x0a, 0x0e, 0x4e, 0x6f,
+	0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e,
+	0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x75, 0x70, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x02, 0x75, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x22,
+	0x0a, 0x0d, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x55, 0x6e, 0x69, 0x78,
+	0x4d, 0x73, 0x2a, 0xc0, 0x01, 0x0a, 0x0a, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x64,
+	0x65, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x4f, 0x4b, 0x10, 0x00,
+	0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x50, 0x41, 0x52, 0x53, 0x45,
+	0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x52, 0x45, 0x53, 0x55,
+	0x4c, 0x54, 0x5f, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f,
+	0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x52, 0x45, 0x41, 0x43, 0x48, 0x41, 0x42, 0x4c, 0x45,
+	0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x4e, 0x4f, 0x44,
+	0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f, 0x55, 0x54, 0x10, 0x04, 0x12, 0x1d, 0x0a, 0x19, 0x52,
+	0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x4f, 0x52,
+	0x5f, 0x46, 0x41, 0x49, 0x4c, 0x55, 0x52, 0x45, 0x10, 0x05, 0x12, 0x19, 0x0a, 0x15, 0x52, 0x45,
+	0x53, 0x55, 0x4c, 0x54, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x5f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x10, 0x06, 0x32, 0xe9, 0x03, 0x0a, 0x0a, 0x44, 0x77, 0x6d, 0x53, 0x65, 0x72,
 	0x76, 0x69, 0x63, 0x65, 0x12, 0x2f, 0x0a, 0x12, 0x44, 0x77, 0x6d, 0x53, 0x65, 0x74, 0x53, 0x79,
 	0x73, 0x74, 0x65, 0x6d, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x0a, 0x2e, 0x64, 0x77, 0x6d,
 	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0d, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x52, 0x65, 0x73,
@@ -188,9 +1409,31 @@ var file_proto_dwm_proto_rawDesc = []byte{
 	0x61, 0x79, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x2e, 0x64,
 	0x77, 0x6d, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
 	0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x64, 0x77, 0x6d,
-	0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x0e, 0x5a, 0x0c, 0x67, 0x72, 0x70,
-	0x63, 0x2f, 0x64, 0x77, 0x6d, 0x3b, 0x64, 0x77, 0x6d, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x44, 0x77, 0x6d,
+	0x47, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x15, 0x2e, 0x64, 0x77, 0x6d, 0x2e,
+	0x47, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x0e, 0x44, 0x77, 0x6d, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x0a, 0x2e, 0x64, 0x77, 0x6d,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x10, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x0d, 0x44, 0x77,
+	0x6d, 0x55, 0x6e, 0x64, 0x6f, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x2e, 0x64, 0x77,
+	0x6d, 0x2e, 0x55, 0x6e, 0x64, 0x6f, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x46, 0x0a, 0x15, 0x44, 0x77, 0x6d, 0x52, 0x65, 0x76, 0x65, 0x72, 0x74, 0x54,
+	0x6f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x2e, 0x64, 0x77,
+	0x6d, 0x2e, 0x52, 0x65, 0x76, 0x65, 0x72, 0x74, 0x54, 0x6f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x64, 0x77,
+	0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x13, 0x44, 0x77,
+	0x6d, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x0a, 0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1a, 0x2e,
+	0x64, 0x77, 0x6d, 0x2e, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x11, 0x44, 0x77, 0x6d,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0a,
+	0x2e, 0x64, 0x77, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x64, 0x77, 0x6d,
+	0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30,
+	0x01, 0x42, 0x0e, 0x5a, 0x0c, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x64, 0x77, 0x6d, 0x3b, 0x64, 0x77,
+	0x6d, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -205,22 +1448,59 @@ func file_proto_dwm_proto_rawDescGZIP() []byte {
 	return file_proto_dwm_proto_rawDescData
 }
 
-var file_proto_dwm_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_dwm_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_dwm_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_proto_dwm_proto_goTypes = []interface{}{
-	(*Empty)(nil),                   // 0: dwm.Empty
-	(*SetLayoutCommandRequest)(nil), // 1: dwm.SetLayoutCommandRequest
-	(*Response)(nil),                // 2: dwm.Response
+	(ResultCode)(0),                   // 0: dwm.ResultCode
+	(*Empty)(nil),                     // 1: dwm.Empty
+	(*SetLayoutCommandRequest)(nil),   // 2: dwm.SetLayoutCommandRequest
+	(*NodeResult)(nil),                // 3: dwm.NodeResult
+	(*CompositorCommand)(nil),         // 4: dwm.CompositorCommand
+	(*DryRunNodeResult)(nil),          // 5: dwm.DryRunNodeResult
+	(*Response)(nil),                  // 6: dwm.Response
+	(*GetLayoutRequest)(nil),          // 7: dwm.GetLayoutRequest
+	(*NodePixelScreens)(nil),          // 8: dwm.NodePixelScreens
+	(*GetLayoutResponse)(nil),         // 9: dwm.GetLayoutResponse
+	(*IdPair)(nil),                    // 10: dwm.IdPair
+	(*LayoutEvent)(nil),               // 11: dwm.LayoutEvent
+	(*UndoLayoutRequest)(nil),         // 12: dwm.UndoLayoutRequest
+	(*RevertToGenerationRequest)(nil), // 13: dwm.RevertToGenerationRequest
+	(*RDisplayStatus)(nil),            // 14: dwm.RDisplayStatus
+	(*NodeStatus)(nil),                // 15: dwm.NodeStatus
+	(*ClusterStatusResponse)(nil),     // 16: dwm.ClusterStatusResponse
+	(*NodeStateEvent)(nil),            // 17: dwm.NodeStateEvent
 }
 var file_proto_dwm_proto_depIdxs = []int32{
-	0, // 0: dwm.DwmService.DwmSetSystemLayout:input_type -> dwm.Empty
-	1, // 1: dwm.DwmService.DwmSetLayoutCommand:input_type -> dwm.SetLayoutCommandRequest
-	2, // 2: dwm.DwmService.DwmSetSystemLayout:output_type -> dwm.Response
-	2, // 3: dwm.DwmService.DwmSetLayoutCommand:output_type -> dwm.Response
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: dwm.NodeResult.code:type_name -> dwm.ResultCode
+	4,  // 1: dwm.DryRunNodeResult.compositor_commands:type_name -> dwm.CompositorCommand
+	3,  // 2: dwm.Response.node_results:type_name -> dwm.NodeResult
+	0,  // 3: dwm.Response.code:type_name -> dwm.ResultCode
+	5,  // 4: dwm.Response.dry_run_results:type_name -> dwm.DryRunNodeResult
+	8,  // 5: dwm.GetLayoutResponse.node_pixel_screens:type_name -> dwm.NodePixelScreens
+	10, // 6: dwm.LayoutEvent.chg_ids:type_name -> dwm.IdPair
+	14, // 7: dwm.NodeStatus.rdisplays:type_name -> dwm.RDisplayStatus
+	15, // 8: dwm.ClusterStatusResponse.nodes:type_name -> dwm.NodeStatus
+	1,  // 9: dwm.DwmService.DwmSetSystemLayout:input_type -> dwm.Empty
+	2,  // 10: dwm.DwmService.DwmSetLayoutCommand:input_type -> dwm.SetLayoutCommandRequest
+	7,  // 11: dwm.DwmService.DwmGetLayout:input_type -> dwm.GetLayoutRequest
+	1,  // 12: dwm.DwmService.DwmWatchLayout:input_type -> dwm.Empty
+	12, // 13: dwm.DwmService.DwmUndoLayout:input_type -> dwm.UndoLayoutRequest
+	13, // 14: dwm.DwmService.DwmRevertToGeneration:input_type -> dwm.RevertToGenerationRequest
+	1,  // 15: dwm.DwmService.DwmGetClusterStatus:input_type -> dwm.Empty
+	1,  // 16: dwm.DwmService.DwmWatchNodeState:input_type -> dwm.Empty
+	6,  // 17: dwm.DwmService.DwmSetSystemLayout:output_type -> dwm.Response
+	6,  // 18: dwm.DwmService.DwmSetLayoutCommand:output_type -> dwm.Response
+	9,  // 19: dwm.DwmService.DwmGetLayout:output_type -> dwm.GetLayoutResponse
+	11, // 20: dwm.DwmService.DwmWatchLayout:output_type -> dwm.LayoutEvent
+	6,  // 21: dwm.DwmService.DwmUndoLayout:output_type -> dwm.Response
+	6,  // 22: dwm.DwmService.DwmRevertToGeneration:output_type -> dwm.Response
+	16, // 23: dwm.DwmService.DwmGetClusterStatus:output_type -> dwm.ClusterStatusResponse
+	17, // 24: dwm.DwmService.DwmWatchNodeState:output_type -> dwm.NodeStateEvent
+	17, // [17:25] is the sub-list for method output_type
+	9,  // [9:17] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_dwm_proto_init() }
@@ -254,6 +1534,42 @@ func file_proto_dwm_proto_init() {
 			}
 		}
 		file_proto_dwm_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodeResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompositorCommand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DryRunNodeResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Response); i {
 			case 0:
 				return &v.state
@@ -265,19 +1581,152 @@ func file_proto_dwm_proto_init() {
 				return nil
 			}
 		}
+		file_proto_dwm_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLayoutRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodePixelScreens); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLayoutResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IdPair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LayoutEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UndoLayoutRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RevertToGenerationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RDisplayStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodeStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_dwm_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodeStateEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_dwm_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_dwm_proto_goTypes,
 		DependencyIndexes: file_proto_dwm_proto_depIdxs,
+		EnumInfos:         file_proto_dwm_proto_enumTypes,
 		MessageInfos:      file_proto_dwm_proto_msgTypes,
 	}.Build()
 	File_proto_dwm_proto = out.File
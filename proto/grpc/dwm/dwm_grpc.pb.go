@@ -36,8 +36,14 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	DwmService_DwmSetSystemLayout_FullMethodName  = "/dwm.DwmService/DwmSetSystemLayout"
-	DwmService_DwmSetLayoutCommand_FullMethodName = "/dwm.DwmService/DwmSetLayoutCommand"
+	DwmService_DwmSetSystemLayout_FullMethodName    = "/dwm.DwmService/DwmSetSystemLayout"
+	DwmService_DwmSetLayoutCommand_FullMethodName   = "/dwm.DwmService/DwmSetLayoutCommand"
+	DwmService_DwmGetLayout_FullMethodName          = "/dwm.DwmService/DwmGetLayout"
+	DwmService_DwmWatchLayout_FullMethodName        = "/dwm.DwmService/DwmWatchLayout"
+	DwmService_DwmUndoLayout_FullMethodName         = "/dwm.DwmService/DwmUndoLayout"
+	DwmService_DwmRevertToGeneration_FullMethodName = "/dwm.DwmService/DwmRevertToGeneration"
+	DwmService_DwmGetClusterStatus_FullMethodName   = "/dwm.DwmService/DwmGetClusterStatus"
+	DwmService_DwmWatchNodeState_FullMethodName     = "/dwm.DwmService/DwmWatchNodeState"
 )
 
 // DwmServiceClient is the client API for DwmService service.
@@ -46,6 +52,12 @@ const (
 type DwmServiceClient interface {
 	DwmSetSystemLayout(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Response, error)
 	DwmSetLayoutCommand(ctx context.Context, in *SetLayoutCommandRequest, opts ...grpc.CallOption) (*Response, error)
+	DwmGetLayout(ctx context.Context, in *GetLayoutRequest, opts ...grpc.CallOption) (*GetLayoutResponse, error)
+	DwmWatchLayout(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DwmService_DwmWatchLayoutClient, error)
+	DwmUndoLayout(ctx context.Context, in *UndoLayoutRequest, opts ...grpc.CallOption) (*Response, error)
+	DwmRevertToGeneration(ctx context.Context, in *RevertToGenerationRequest, opts ...grpc.CallOption) (*Response, error)
+	DwmGetClusterStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ClusterStatusResponse, error)
+	DwmWatchNodeState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DwmService_DwmWatchNodeStateClient, error)
 }
 
 type dwmServiceClient struct {
@@ -74,12 +86,118 @@ func (c *dwmServiceClient) DwmSetLayoutCommand(ctx context.Context, in *SetLayou
 	return out, nil
 }
 
+func (c *dwmServiceClient) DwmGetLayout(ctx context.Context, in *GetLayoutRequest, opts ...grpc.CallOption) (*GetLayoutResponse, error) {
+	out := new(GetLayoutResponse)
+	err := c.cc.Invoke(ctx, DwmService_DwmGetLayout_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dwmServiceClient) DwmWatchLayout(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DwmService_DwmWatchLayoutClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DwmService_ServiceDesc.Streams[0], DwmService_DwmWatchLayout_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dwmServiceDwmWatchLayoutClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DwmService_DwmWatchLayoutClient interface {
+	Recv() (*LayoutEvent, error)
+	grpc.ClientStream
+}
+
+type dwmServiceDwmWatchLayoutClient struct {
+	grpc.ClientStream
+}
+
+func (x *dwmServiceDwmWatchLayoutClient) Recv() (*LayoutEvent, error) {
+	m := new(LayoutEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dwmServiceClient) DwmUndoLayout(ctx context.Context, in *UndoLayoutRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, DwmService_DwmUndoLayout_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dwmServiceClient) DwmRevertToGeneration(ctx context.Context, in *RevertToGenerationRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, DwmService_DwmRevertToGeneration_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dwmServiceClient) DwmGetClusterStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ClusterStatusResponse, error) {
+	out := new(ClusterStatusResponse)
+	err := c.cc.Invoke(ctx, DwmService_DwmGetClusterStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dwmServiceClient) DwmWatchNodeState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DwmService_DwmWatchNodeStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DwmService_ServiceDesc.Streams[1], DwmService_DwmWatchNodeState_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dwmServiceDwmWatchNodeStateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DwmService_DwmWatchNodeStateClient interface {
+	Recv() (*NodeStateEvent, error)
+	grpc.ClientStream
+}
+
+type dwmServiceDwmWatchNodeStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *dwmServiceDwmWatchNodeStateClient) Recv() (*NodeStateEvent, error) {
+	m := new(NodeStateEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DwmServiceServer is the server API for DwmService service.
 // All implementations must embed UnimplementedDwmServiceServer
 // for forward compatibility
 type DwmServiceServer interface {
 	DwmSetSystemLayout(context.Context, *Empty) (*Response, error)
 	DwmSetLayoutCommand(context.Context, *SetLayoutCommandRequest) (*Response, error)
+	DwmGetLayout(context.Context, *GetLayoutRequest) (*GetLayoutResponse, error)
+	DwmWatchLayout(*Empty, DwmService_DwmWatchLayoutServer) error
+	DwmUndoLayout(context.Context, *UndoLayoutRequest) (*Response, error)
+	DwmRevertToGeneration(context.Context, *RevertToGenerationRequest) (*Response, error)
+	DwmGetClusterStatus(context.Context, *Empty) (*ClusterStatusResponse, error)
+	DwmWatchNodeState(*Empty, DwmService_DwmWatchNodeStateServer) error
 	mustEmbedUnimplementedDwmServiceServer()
 }
 
@@ -93,6 +211,24 @@ func (UnimplementedDwmServiceServer) DwmSetSystemLayout(context.Context, *Empty)
 func (UnimplementedDwmServiceServer) DwmSetLayoutCommand(context.Context, *SetLayoutCommandRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DwmSetLayoutCommand not implemented")
 }
+func (UnimplementedDwmServiceServer) DwmGetLayout(context.Context, *GetLayoutRequest) (*GetLayoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DwmGetLayout not implemented")
+}
+func (UnimplementedDwmServiceServer) DwmWatchLayout(*Empty, DwmService_DwmWatchLayoutServer) error {
+	return status.Errorf(codes.Unimplemented, "method DwmWatchLayout not implemented")
+}
+func (UnimplementedDwmServiceServer) DwmUndoLayout(context.Context, *UndoLayoutRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DwmUndoLayout not implemented")
+}
+func (UnimplementedDwmServiceServer) DwmRevertToGeneration(context.Context, *RevertToGenerationRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DwmRevertToGeneration not implemented")
+}
+func (UnimplementedDwmServiceServer) DwmGetClusterStatus(context.Context, *Empty) (*ClusterStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DwmGetClusterStatus not implemented")
+}
+func (UnimplementedDwmServiceServer) DwmWatchNodeState(*Empty, DwmService_DwmWatchNodeStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method DwmWatchNodeState not implemented")
+}
 func (UnimplementedDwmServiceServer) mustEmbedUnimplementedDwmServiceServer() {}
 
 // UnsafeDwmServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -142,6 +278,120 @@ func _DwmService_DwmSetLayoutCommand_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DwmService_DwmGetLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DwmServiceServer).DwmGetLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DwmService_DwmGetLayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DwmServiceServer).DwmGetLayout(ctx, req.(*GetLayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DwmService_DwmWatchLayout_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DwmServiceServer).DwmWatchLayout(m, &dwmServiceDwmWatchLayoutServer{stream})
+}
+
+type DwmService_DwmWatchLayoutServer interface {
+	Send(*LayoutEvent) error
+	grpc.ServerStream
+}
+
+type dwmServiceDwmWatchLayoutServer struct {
+	grpc.ServerStream
+}
+
+func (x *dwmServiceDwmWatchLayoutServer) Send(m *LayoutEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DwmService_DwmUndoLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoLayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DwmServiceServer).DwmUndoLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DwmService_DwmUndoLayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DwmServiceServer).DwmUndoLayout(ctx, req.(*UndoLayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DwmService_DwmRevertToGeneration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertToGenerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DwmServiceServer).DwmRevertToGeneration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DwmService_DwmRevertToGeneration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DwmServiceServer).DwmRevertToGeneration(ctx, req.(*RevertToGenerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DwmService_DwmGetClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DwmServiceServer).DwmGetClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DwmService_DwmGetClusterStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DwmServiceServer).DwmGetClusterStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DwmService_DwmWatchNodeState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DwmServiceServer).DwmWatchNodeState(m, &dwmServiceDwmWatchNodeStateServer{stream})
+}
+
+type DwmService_DwmWatchNodeStateServer interface {
+	Send(*NodeStateEvent) error
+	grpc.ServerStream
+}
+
+type dwmServiceDwmWatchNodeStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *dwmServiceDwmWatchNodeStateServer) Send(m *NodeStateEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // DwmService_ServiceDesc is the grpc.ServiceDesc for DwmService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,7 +407,34 @@ var DwmService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DwmSetLayoutCommand",
 			Handler:    _DwmService_DwmSetLayoutCommand_Handler,
 		},
+		{
+			MethodName: "DwmGetLayout",
+			Handler:    _DwmService_DwmGetLayout_Handler,
+		},
+		{
+			MethodName: "DwmUndoLayout",
+			Handler:    _DwmService_DwmUndoLayout_Handler,
+		},
+		{
+			MethodName: "DwmRevertToGeneration",
+			Handler:    _DwmService_DwmRevertToGeneration_Handler,
+		},
+		{
+			MethodName: "DwmGetClusterStatus",
+			Handler:    _DwmService_DwmGetClusterStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DwmWatchLayout",
+			Handler:       _DwmService_DwmWatchLayout_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DwmWatchNodeState",
+			Handler:       _DwmService_DwmWatchNodeState_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/dwm.proto",
 }